@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+// addrLookup is one --addr2sym query resolved against a file. Queries are
+// hex addresses, or "section+offset" for a place in a given section.
+type addrLookup struct {
+	query      string
+	info       elfparse.AddrInfo
+	translated bool // info.Addr isn't the address in the query
+	err        error
+}
+
+func lookupAddrs(elfFs *elfparse.ELFFile, m modes) []addrLookup {
+	var lookups []addrLookup
+	for _, query := range m.addrs {
+		l := addrLookup{query: query}
+		section, off, qualified := strings.Cut(query, "+")
+		if !qualified {
+			off = query
+		}
+
+		addr, err := parseHex(off)
+		switch {
+		case err != nil:
+			l.err = err
+		case qualified:
+			var ndx uint32
+			if ndx, l.err = elfFs.LookupSection(section); l.err == nil {
+				l.info = elfFs.Addr2SymIn(ndx, addr)
+				l.translated = l.info.Addr != addr
+			}
+		default:
+			/* a runtime address of an image loaded at --base */
+			if m.hasBase {
+				addr = addr - m.base + elfFs.ImageBase()
+				l.translated = true
+			}
+			l.info = elfFs.Addr2Sym(addr)
+		}
+		lookups = append(lookups, l)
+	}
+	return lookups
+}
+
+// readStdinAddrs replaces an --addr2sym=- query by the queries on stdin,
+// one per line.
+func readStdinAddrs(m *modes) error {
+	var addrs []string
+	for _, query := range m.addrs {
+		if query != "-" {
+			addrs = append(addrs, query)
+			continue
+		}
+		sc := bufio.NewScanner(os.Stdin)
+		for sc.Scan() {
+			if line := strings.TrimSpace(sc.Text()); line != "" {
+				addrs = append(addrs, line)
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return err
+		}
+	}
+	m.addrs = addrs
+	return nil
+}
+
+// parseHex parses an address the way crash logs print them, in hex with
+// or without 0x.
+func parseHex(s string) (uint64, error) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad address '%s'", s)
+	}
+	return v, nil
+}
+
+// addrSymbol is "symbol+0xoff", or "??" when no symbol covers the address.
+func addrSymbol(info elfparse.AddrInfo, demangle bool) string {
+	if !info.HasSymbol {
+		return "??"
+	}
+	name := displayName(info.Name, demangle)
+	if info.Offset == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s+0x%x", name, info.Offset)
+}
+
+func addrSection(elfFs *elfparse.ELFFile, info elfparse.AddrInfo) string {
+	if !info.HasSection || info.Section >= uint32(len(elfFs.ElfSections.SectionName)) {
+		return ""
+	}
+	return elfFs.ElfSections.SectionName[info.Section]
+}
+
+// printAddr2Sym prints a line per query, like
+// "0x401136: main+0x6 (section .text, segment 2)".
+func printAddr2Sym(elfFs *elfparse.ELFFile, m modes) {
+	for _, l := range lookupAddrs(elfFs, m) {
+		if l.err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", l.query, l.err)
+			continue
+		}
+
+		where := []string{"no section"}
+		if name := addrSection(elfFs, l.info); name != "" {
+			where[0] = "section " + name
+		}
+		if l.info.Segment >= 0 {
+			where = append(where, fmt.Sprintf("segment %d", l.info.Segment))
+		} else if len(elfFs.ProgHeaders) > 0 {
+			where = append(where, "no segment")
+		}
+
+		query := l.query
+		if l.translated {
+			query += fmt.Sprintf(" = 0x%x", l.info.Addr)
+		}
+		fmt.Printf("%s: %s (%s)\n", query, addrSymbol(l.info, m.demangle), strings.Join(where, ", "))
+	}
+}
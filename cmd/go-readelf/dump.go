@@ -0,0 +1,133 @@
+package main
+
+import (
+	"debug/elf"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+// printDumps prints the -x and -p requests in the order they were given,
+// in readelf's layout.
+func printDumps(w io.Writer, elfFs *elfparse.ELFFile, dumps []dump) {
+	for _, d := range dumps {
+		ndx, err := elfFs.LookupSection(d.section)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: section '%s' was not dumped because it does not exist\n", d.section)
+			continue
+		}
+		dumpSection(w, elfFs, ndx, d.strings)
+	}
+}
+
+// dumpSection prints section ndx as a -x or, with strings, a -p dump.
+func dumpSection(w io.Writer, elfFs *elfparse.ELFFile, ndx uint32, strings bool) {
+	name := elfFs.ElfSections.SectionName[ndx]
+	sec := elfFs.ElfSections.Section[ndx]
+	if sec.Type == elf.SHT_NOBITS || sec.Size == 0 {
+		fmt.Fprintf(w, "Section '%s' has no data to dump.\n", name)
+		return
+	}
+
+	data, err := elfFs.SectionContents(ndx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: section '%s' was not dumped: %v\n", name, err)
+		return
+	}
+	if strings {
+		printStringDump(w, name, data, hasRelocations(elfFs, ndx))
+	} else {
+		printHexDump(w, name, sec.Addr, data, hasRelocations(elfFs, ndx))
+	}
+}
+
+func printHexDump(w io.Writer, name string, addr uint64, data []byte, relocated bool) {
+	fmt.Fprintf(w, "\nHex dump of section '%s':\n", name)
+	if relocated {
+		fmt.Fprintln(w, " NOTE: This section has relocations against it, but these have NOT been applied to this dump.")
+	}
+	for off := 0; off < len(data); off += 16 {
+		line := data[off:min(off+16, len(data))]
+
+		fmt.Fprintf(w, "  0x%08x ", addr+uint64(off))
+		for j := 0; j < 16; j++ {
+			if j < len(line) {
+				fmt.Fprintf(w, "%02x", line[j])
+			} else {
+				fmt.Fprintf(w, "  ")
+			}
+			if j&3 == 3 {
+				fmt.Fprintf(w, " ")
+			}
+		}
+		for _, c := range line {
+			if c < ' ' || c >= 0x7f {
+				c = '.'
+			}
+			fmt.Fprintf(w, "%c", c)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+}
+
+// printStringDump prints every run of printable characters with its
+// offset in the section. Newlines end a string, other control characters
+// are shown as ^X.
+func printStringDump(w io.Writer, name string, data []byte, relocated bool) {
+	fmt.Fprintf(w, "\nString dump of section '%s':\n", name)
+	if relocated {
+		fmt.Fprintln(w, "  Note: This section has relocations against it, but these have NOT been applied to this dump.")
+	}
+
+	found := false
+	for i := 0; i < len(data); {
+		if !isPrint(data[i]) {
+			i++
+			continue
+		}
+
+		found = true
+		fmt.Fprintf(w, "  [%6x]  ", i)
+		for ; i < len(data) && data[i] != 0; i++ {
+			switch c := data[i]; {
+			case c == '\n':
+				fmt.Fprintf(w, "\\n\n")
+				if i+1 < len(data) && data[i+1] != 0 {
+					fmt.Fprintf(w, "  [%6x]  ", i+1)
+				}
+			case isPrint(c) || c >= 0x80:
+				w.Write([]byte{c})
+			default:
+				fmt.Fprintf(w, "^%c", c+0x40)
+			}
+		}
+		if data[i-1] != '\n' {
+			fmt.Fprintln(w)
+		}
+	}
+	if !found {
+		fmt.Fprintf(w, "  No strings found in this section.")
+	}
+	fmt.Fprintln(w)
+}
+
+// hasRelocations reports whether a relocation section of an object file
+// applies to section ndx.
+func hasRelocations(elfFs *elfparse.ELFFile, ndx uint32) bool {
+	if elfFs.Hdr.Type != elf.ET_REL {
+		return false
+	}
+	for k := range elfFs.Rels {
+		if elfFs.ElfSections.Section[k].Info == ndx {
+			return true
+		}
+	}
+	return false
+}
+
+func isPrint(c byte) bool {
+	return c >= ' ' && c < 0x7f
+}
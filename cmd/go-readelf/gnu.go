@@ -0,0 +1,1506 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+// The --compat=gnu renderer reproduces the layout of binutils
+// "readelf -W", so scripts written against it keep working. Tables come
+// out in the order readelf prints them, not the order of the options.
+
+func printGNU(w io.Writer, elfFs *elfparse.ELFFile, m modes) {
+	if m.header {
+		gnuHeader(w, elfFs)
+	}
+	if m.sections {
+		gnuSections(w, elfFs, m.header, m.sectionDetails)
+	}
+	if m.progHeaders {
+		gnuSegments(w, elfFs, m.header)
+	}
+	if m.dynamic {
+		gnuDynamic(w, elfFs)
+	}
+	switch {
+	case m.relocSummary:
+		printRelocSummary(elfFs)
+	case m.relocations:
+		gnuRelocations(w, elfFs, m.demangle)
+	}
+	if m.symbols {
+		gnuSymbols(w, elfFs, m.symQuery, m.demangle)
+	}
+	if m.histogram {
+		gnuHistograms(w, elfFs)
+		warnHashProblems(elfFs, m.demangle)
+	}
+	if m.versions {
+		gnuVersions(w, elfFs)
+	}
+	gnuDumps(w, elfFs, m.dumps)
+	if m.notes {
+		gnuNotes(w, elfFs)
+	}
+	printAddr2Sym(elfFs, m)
+	printLookups(elfFs, m)
+}
+
+// cHex is C's "%#x", which prints 0 without the 0x.
+func cHex(v uint64) string {
+	if v == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%#x", v)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// gnuNoSections is true when readelf would see no section headers; the
+// ones synthesized from the dynamic segment don't count.
+func gnuNoSections(elfFs *elfparse.ELFFile) bool {
+	return len(elfFs.ElfSections.Section) == 0 || elfFs.ElfSections.Section[0].Synthetic
+}
+
+func is32(elfFs *elfparse.ELFFile) bool {
+	return elfFs.FileHdr.Arch == elf.ELFCLASS32
+}
+
+/* file header */
+
+var gnuOSABI = map[elf.OSABI]string{
+	elf.ELFOSABI_NONE:     "UNIX - System V",
+	elf.ELFOSABI_HPUX:     "UNIX - HP-UX",
+	elf.ELFOSABI_NETBSD:   "UNIX - NetBSD",
+	elf.ELFOSABI_LINUX:    "UNIX - GNU",
+	elf.ELFOSABI_SOLARIS:  "UNIX - Solaris",
+	elf.ELFOSABI_AIX:      "UNIX - AIX",
+	elf.ELFOSABI_IRIX:     "UNIX - IRIX",
+	elf.ELFOSABI_FREEBSD:  "UNIX - FreeBSD",
+	elf.ELFOSABI_TRU64:    "UNIX - TRU64",
+	elf.ELFOSABI_MODESTO:  "Novell - Modesto",
+	elf.ELFOSABI_OPENBSD:  "UNIX - OpenBSD",
+	elf.ELFOSABI_OPENVMS:  "VMS - OpenVMS",
+	elf.ELFOSABI_NSK:      "HP - Non-Stop Kernel",
+	elf.ELFOSABI_AROS:     "AROS",
+	elf.ELFOSABI_FENIXOS:  "FenixOS",
+	elf.ELFOSABI_CLOUDABI: "Nuxi CloudABI",
+	elf.OSABI(18):         "Stratus Technologies OpenVOS",
+}
+
+var gnuMachines = map[elf.Machine]string{
+	elf.EM_NONE:        "None",
+	elf.EM_M32:         "WE32100",
+	elf.EM_SPARC:       "Sparc",
+	elf.EM_386:         "Intel 80386",
+	elf.EM_68K:         "MC68000",
+	elf.EM_88K:         "MC88000",
+	elf.Machine(6):     "Intel MCU",
+	elf.EM_860:         "Intel 80860",
+	elf.EM_MIPS:        "MIPS R3000",
+	elf.EM_S370:        "IBM System/370",
+	elf.EM_MIPS_RS3_LE: "MIPS R4000 big-endian",
+	elf.EM_PARISC:      "HPPA",
+	elf.EM_SPARC32PLUS: "Sparc v8+",
+	elf.EM_960:         "Intel 80960",
+	elf.EM_PPC:         "PowerPC",
+	elf.EM_PPC64:       "PowerPC64",
+	elf.EM_S390:        "IBM S/390",
+	elf.Machine(23):    "SPU",
+	elf.EM_V800:        "Renesas V850 (using RH850 ABI)",
+	elf.EM_FR20:        "Fujitsu FR20",
+	elf.EM_RH32:        "TRW RH32",
+	elf.EM_ARM:         "ARM",
+	elf.EM_ALPHA:       "Alpha",
+	elf.EM_SH:          "Renesas / SuperH SH",
+	elf.EM_SPARCV9:     "Sparc v9",
+	elf.EM_TRICORE:     "Siemens Tricore",
+	elf.EM_ARC:         "ARC",
+	elf.EM_H8_300:      "Renesas H8/300",
+	elf.EM_H8_300H:     "Renesas H8/300H",
+	elf.EM_H8S:         "Renesas H8S",
+	elf.EM_H8_500:      "Renesas H8/500",
+	elf.EM_IA_64:       "Intel IA-64",
+	elf.EM_MIPS_X:      "Stanford MIPS-X",
+	elf.EM_COLDFIRE:    "Motorola Coldfire",
+	elf.EM_68HC12:      "Motorola MC68HC12 Microcontroller",
+	elf.EM_X86_64:      "Advanced Micro Devices X86-64",
+	elf.EM_AVR:         "Atmel AVR 8-bit microcontroller",
+	elf.EM_MSP430:      "Texas Instruments msp430 microcontroller",
+	elf.EM_XTENSA:      "Tensilica Xtensa Processor",
+	elf.EM_AARCH64:     "AArch64",
+	elf.EM_TILEGX:      "Tilera TILE-Gx multicore architecture family",
+	elf.EM_CUDA:        "NVIDIA CUDA architecture",
+	elf.EM_AMDGPU:      "AMD GPU",
+	elf.EM_RISCV:       "RISC-V",
+	elf.EM_BPF:         "Linux BPF",
+	elf.EM_LOONGARCH:   "LoongArch",
+}
+
+func gnuFileType(elfFs *elfparse.ELFFile) string {
+	t := elfFs.Hdr.Type
+	switch {
+	case t == elf.ET_NONE:
+		return "NONE (None)"
+	case t == elf.ET_REL:
+		return "REL (Relocatable file)"
+	case t == elf.ET_EXEC:
+		return "EXEC (Executable file)"
+	case t == elf.ET_DYN:
+		if flags, ok := elfFs.DynValue(elf.DT_FLAGS_1); ok && elf.DynFlag1(flags)&elf.DF_1_PIE != 0 {
+			return "DYN (Position-Independent Executable file)"
+		}
+		return "DYN (Shared object file)"
+	case t == elf.ET_CORE:
+		return "CORE (Core file)"
+	case t >= elf.ET_LOPROC:
+		return fmt.Sprintf("Processor Specific: (%x)", uint16(t))
+	case t >= elf.ET_LOOS && t <= elf.ET_HIOS:
+		return fmt.Sprintf("OS Specific: (%x)", uint16(t))
+	}
+	return fmt.Sprintf("<unknown>: %x", uint16(t))
+}
+
+type gnuFlag struct {
+	bit  uint32
+	name string
+}
+
+// gnuARMFlags are the e_flags bits each ARM EABI version defines, the
+// pre-EABI GNU ones under 0. Version 3 defines none and readelf does not
+// look at its bits.
+var gnuARMFlags = map[uint32][]gnuFlag{
+	0: {
+		{0x4, "interworking enabled"}, {0x8, "uses APCS/26"}, {0x10, "uses APCS/float"},
+		{0x40, "8 bit structure alignment"}, {0x80, "uses new ABI"}, {0x100, "uses old ABI"},
+		{0x200, "software FP"}, {0x400, "VFP"}, {0x800, "Maverick FP"},
+	},
+	1: {{0x4, "sorted symbol tables"}},
+	2: {{0x4, "sorted symbol tables"}, {0x8, "dynamic symbols use segment index"}, {0x10, "mapping symbols precede others"}},
+	3: nil,
+	4: {{0x400000, "LE8"}, {0x800000, "BE8"}},
+	5: {{0x200, "soft-float ABI"}, {0x400, "hard-float ABI"}, {0x400000, "LE8"}, {0x800000, "BE8"}},
+}
+
+var gnuMIPSFlags = []gnuFlag{
+	{0x1, "noreorder"}, {0x2, "pic"}, {0x4, "cpic"}, {0x10, "ugen_reserved"}, {0x20, "abi2"},
+	{0x80, "odk first"}, {0x100, "32bitmode"}, {0x400, "nan2008"}, {0x200, "fp64"},
+}
+
+var gnuMIPSMachs = map[uint32]string{
+	0x00810000: "3900", 0x00820000: "4010", 0x00830000: "4100", 0x00850000: "4650",
+	0x00870000: "4120", 0x00880000: "4111", 0x008a0000: "sb1", 0x008b0000: "octeon",
+	0x008c0000: "xlr", 0x008d0000: "octeon2", 0x008e0000: "octeon3", 0x00910000: "5400",
+	0x00920000: "5900", 0x00930000: "interaptiv-mr2", 0x00980000: "5500", 0x00990000: "9000", 0x00a00000: "loongson-2e",
+	0x00a10000: "loongson-2f", 0x00a20000: "gs464", 0x00a30000: "gs464e", 0x00a40000: "gs264e",
+}
+
+var gnuMIPSASEs = []gnuFlag{{0x08000000, "mdmx"}, {0x04000000, "mips16"}, {0x02000000, "micromips"}}
+
+var gnuMIPSISAs = map[uint32]string{
+	0x0: "mips1", 0x1: "mips2", 0x2: "mips3", 0x3: "mips4", 0x4: "mips5", 0x5: "mips32",
+	0x6: "mips64", 0x7: "mips32r2", 0x8: "mips64r2", 0x9: "mips32r6", 0xa: "mips64r6",
+}
+
+// gnuMachineFlags decodes the e_flags of the machines whose flags readelf
+// spells out and that are common enough to matter here.
+func gnuMachineFlags(h elfparse.Header) string {
+	var s string
+	if h.Flags == 0 {
+		return s
+	}
+	switch h.Machine {
+	case elf.EM_ARM:
+		eabi, flags := h.Flags>>24, h.Flags&0xffffff
+		if flags&0x1 != 0 {
+			s += ", relocatable executable"
+		}
+		if flags&0x20 != 0 {
+			s += ", position independent"
+		}
+		flags &^= 0x21
+		known, ok := gnuARMFlags[eabi]
+		switch {
+		case eabi == 0:
+			s += ", GNU EABI"
+		case !ok:
+			s += ", <unrecognized EABI>"
+		case eabi == 3:
+			s += ", Version3 EABI"
+			flags = 0
+		default:
+			s += fmt.Sprintf(", Version%d EABI", eabi)
+		}
+		unknown := false
+		for ; flags != 0; flags &= flags - 1 {
+			name := ""
+			for _, f := range known {
+				if f.bit == flags&-flags {
+					name = f.name
+				}
+			}
+			if name == "" {
+				unknown = true
+				continue
+			}
+			s += ", " + name
+		}
+		if unknown {
+			s += ", <unknown>"
+		}
+	case elf.EM_MIPS, elf.EM_MIPS_RS3_LE:
+		for _, f := range gnuMIPSFlags {
+			if h.Flags&f.bit != 0 {
+				s += ", " + f.name
+			}
+		}
+		switch mach := h.Flags & 0x00ff0000; {
+		case mach == 0:
+		case gnuMIPSMachs[mach] != "":
+			s += ", " + gnuMIPSMachs[mach]
+		default:
+			s += ", unknown CPU"
+		}
+		switch h.Flags & 0xf000 {
+		case 0:
+		case 0x1000:
+			s += ", o32"
+		case 0x2000:
+			s += ", o64"
+		case 0x3000:
+			s += ", eabi32"
+		case 0x4000:
+			s += ", eabi64"
+		default:
+			s += ", unknown ABI"
+		}
+		for _, f := range gnuMIPSASEs {
+			if h.Flags&f.bit != 0 {
+				s += ", " + f.name
+			}
+		}
+		if isa, ok := gnuMIPSISAs[h.Flags>>28]; ok {
+			s += ", " + isa
+		} else {
+			s += ", unknown ISA"
+		}
+	case elf.EM_RISCV:
+		if h.Flags&0x1 != 0 {
+			s += ", RVC"
+		}
+		if h.Flags&0x8 != 0 {
+			s += ", RVE"
+		}
+		if h.Flags&0x10 != 0 {
+			s += ", TSO"
+		}
+		switch h.Flags & 0x6 {
+		case 0x0:
+			s += ", soft-float ABI"
+		case 0x2:
+			s += ", single-float ABI"
+		case 0x4:
+			s += ", double-float ABI"
+		case 0x6:
+			s += ", quad-float ABI"
+		}
+	}
+	return s
+}
+
+func gnuHeader(w io.Writer, elfFs *elfparse.ELFFile) {
+	h := elfFs.Hdr
+	id := h.Ident
+
+	class := map[elf.Class]string{elf.ELFCLASSNONE: "none", elf.ELFCLASS32: "ELF32", elf.ELFCLASS64: "ELF64"}[elf.Class(id[elf.EI_CLASS])]
+	data := map[elf.Data]string{elf.ELFDATANONE: "none", elf.ELFDATA2LSB: "2's complement, little endian",
+		elf.ELFDATA2MSB: "2's complement, big endian"}[elf.Data(id[elf.EI_DATA])]
+	version := ""
+	switch elf.Version(id[elf.EI_VERSION]) {
+	case elf.EV_CURRENT:
+		version = " (current)"
+	case elf.EV_NONE:
+	default:
+		version = " <unknown>"
+	}
+	osabi, ok := gnuOSABI[elf.OSABI(id[elf.EI_OSABI])]
+	if !ok {
+		osabi = fmt.Sprintf("<unknown: %x>", id[elf.EI_OSABI])
+	}
+	machine, ok := gnuMachines[h.Machine]
+	if !ok {
+		machine = fmt.Sprintf("<unknown>: 0x%x", uint16(h.Machine))
+	}
+
+	fmt.Fprintln(w, "ELF Header:")
+	fmt.Fprintf(w, "  Magic:   ")
+	for _, b := range id {
+		fmt.Fprintf(w, "%02x ", b)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Class:                             %s\n", class)
+	fmt.Fprintf(w, "  Data:                              %s\n", data)
+	fmt.Fprintf(w, "  Version:                           %d%s\n", id[elf.EI_VERSION], version)
+	fmt.Fprintf(w, "  OS/ABI:                            %s\n", osabi)
+	fmt.Fprintf(w, "  ABI Version:                       %d\n", id[elf.EI_ABIVERSION])
+	fmt.Fprintf(w, "  Type:                              %s\n", gnuFileType(elfFs))
+	fmt.Fprintf(w, "  Machine:                           %s\n", machine)
+	fmt.Fprintf(w, "  Version:                           0x%x\n", uint32(h.Version))
+	fmt.Fprintf(w, "  Entry point address:               0x%x\n", h.Entry)
+	fmt.Fprintf(w, "  Start of program headers:          %d (bytes into file)\n", h.Phoff)
+	fmt.Fprintf(w, "  Start of section headers:          %d (bytes into file)\n", h.Shoff)
+	fmt.Fprintf(w, "  Flags:                             0x%x%s\n", h.Flags, gnuMachineFlags(h))
+	fmt.Fprintf(w, "  Size of this header:               %d (bytes)\n", h.Ehsize)
+	fmt.Fprintf(w, "  Size of program headers:           %d (bytes)\n", h.Phentsize)
+	phnum, shnum, shstrndx := headerCounts(h)
+	fmt.Fprintf(w, "  Number of program headers:         %s\n", phnum)
+	fmt.Fprintf(w, "  Size of section headers:           %d (bytes)\n", h.Shentsize)
+	fmt.Fprintf(w, "  Number of section headers:         %s\n", shnum)
+	fmt.Fprintf(w, "  Section header string table index: %s\n", shstrndx)
+}
+
+/* section headers */
+
+var gnuSectionTypes = map[elf.SectionType]string{
+	elf.SHT_NULL:           "NULL",
+	elf.SHT_PROGBITS:       "PROGBITS",
+	elf.SHT_SYMTAB:         "SYMTAB",
+	elf.SHT_STRTAB:         "STRTAB",
+	elf.SHT_RELA:           "RELA",
+	elf.SHT_HASH:           "HASH",
+	elf.SHT_DYNAMIC:        "DYNAMIC",
+	elf.SHT_NOTE:           "NOTE",
+	elf.SHT_NOBITS:         "NOBITS",
+	elf.SHT_REL:            "REL",
+	elf.SHT_SHLIB:          "SHLIB",
+	elf.SHT_DYNSYM:         "DYNSYM",
+	elf.SHT_INIT_ARRAY:     "INIT_ARRAY",
+	elf.SHT_FINI_ARRAY:     "FINI_ARRAY",
+	elf.SHT_PREINIT_ARRAY:  "PREINIT_ARRAY",
+	elf.SHT_GROUP:          "GROUP",
+	elf.SHT_SYMTAB_SHNDX:   "SYMTAB SECTION INDICES",
+	elf.SectionType(19):    "RELR",
+	elf.SHT_GNU_ATTRIBUTES: "GNU_ATTRIBUTES",
+	elf.SHT_GNU_HASH:       "GNU_HASH",
+	elf.SHT_GNU_LIBLIST:    "GNU_LIBLIST",
+	elf.SHT_GNU_VERDEF:     "VERDEF",
+	elf.SHT_GNU_VERNEED:    "VERNEED",
+	elf.SHT_GNU_VERSYM:     "VERSYM",
+	0x6ffffff0 + 8:         "CHECKSUM",
+	0x60000001:             "ANDROID_REL",
+	0x60000002:             "ANDROID_RELA",
+	0x6fffff00:             "ANDROID_RELR",
+	0x6fff4c00:             "LLVM_ODRTAB",
+	0x6fff4c01:             "LLVM_LINKER_OPTIONS",
+	0x6fff4c03:             "LLVM_ADDRSIG",
+	0x6fff4c04:             "LLVM_DEPENDENT_LIBRARIES",
+	0x6fff4c05:             "LLVM_SYMPART",
+	0x6fff4c06:             "LLVM_PART_EHDR",
+	0x6fff4c07:             "LLVM_PART_PHDR",
+	0x6fff4c08:             "LLVM_BB_ADDR_MAP_V0",
+	0x6fff4c09:             "LLVM_CALL_GRAPH_PROFILE",
+	0x6fff4c0a:             "LLVM_BB_ADDR_MAP",
+	0x6fff4c0b:             "LLVM_OFFLOADING",
+	0x6fff4c0c:             "LLVM_LTO",
+}
+
+var gnuProcSectionTypes = map[elf.Machine]map[elf.SectionType]string{
+	elf.EM_X86_64:  {0x70000001: "X86_64_UNWIND"},
+	elf.EM_AARCH64: {0x70000003: "AARCH64_ATTRIBUTES"},
+	elf.EM_RISCV:   {0x70000003: "RISCV_ATTRIBUTES"},
+	elf.EM_ARM: {
+		0x70000001: "ARM_EXIDX",
+		0x70000002: "ARM_PREEMPTMAP",
+		0x70000003: "ARM_ATTRIBUTES",
+		0x70000004: "ARM_DEBUGOVERLAY",
+		0x70000005: "ARM_OVERLAYSECTION",
+	},
+	elf.EM_MIPS: {
+		0x70000006: "MIPS_REGINFO",
+		0x7000000d: "MIPS_OPTIONS",
+		0x7000001e: "MIPS_DWARF",
+		0x7000002a: "MIPS_ABIFLAGS",
+	},
+}
+
+func gnuSectionType(elfFs *elfparse.ELFFile, t elf.SectionType) string {
+	if name, ok := gnuProcSectionTypes[elfFs.FileHdr.Machine][t]; ok {
+		return name
+	}
+	if name, ok := gnuSectionTypes[t]; ok {
+		return name
+	}
+	switch {
+	case t >= elf.SHT_LOPROC && t <= elf.SHT_HIPROC:
+		return "LOPROC+" + cHex(uint64(t-elf.SHT_LOPROC))
+	case t >= elf.SHT_LOOS && t <= elf.SHT_HIOS:
+		return "LOOS+" + cHex(uint64(t-elf.SHT_LOOS))
+	case t >= elf.SHT_LOUSER && t <= elf.SHT_HIUSER:
+		return "LOUSER+" + cHex(uint64(t-elf.SHT_LOUSER))
+	}
+	return fmt.Sprintf("%08x: <unknown>", uint32(t))
+}
+
+const (
+	shfExclude     = 0x80000000
+	shfGNUMbind    = 0x01000000
+	shfGNURetain   = 0x00200000
+	shfX8664Large  = 0x10000000
+	shfARMPurecode = 0x20000000
+)
+
+// gnuSectionFlags builds readelf's flag key, lowest bit first.
+func gnuSectionFlags(elfFs *elfparse.ELFFile, flags elf.SectionFlag) string {
+	keys := map[elf.SectionFlag]byte{
+		elf.SHF_WRITE: 'W', elf.SHF_ALLOC: 'A', elf.SHF_EXECINSTR: 'X', elf.SHF_MERGE: 'M',
+		elf.SHF_STRINGS: 'S', elf.SHF_INFO_LINK: 'I', elf.SHF_LINK_ORDER: 'L',
+		elf.SHF_OS_NONCONFORMING: 'O', elf.SHF_GROUP: 'G', elf.SHF_TLS: 'T',
+		elf.SHF_COMPRESSED: 'C', shfExclude: 'E',
+	}
+	osabi := elf.OSABI(elfFs.Ident[elf.EI_OSABI])
+
+	var b strings.Builder
+	for flags != 0 {
+		flag := flags & -flags
+		flags &^= flag
+
+		if k, ok := keys[flag]; ok {
+			b.WriteByte(k)
+			continue
+		}
+		switch {
+		case flag == shfGNURetain && (osabi == elf.ELFOSABI_LINUX || osabi == elf.ELFOSABI_FREEBSD):
+			b.WriteByte('R')
+		case flag == shfGNUMbind && (osabi == elf.ELFOSABI_NONE || osabi == elf.ELFOSABI_LINUX || osabi == elf.ELFOSABI_FREEBSD):
+			b.WriteByte('D')
+		case flag == shfX8664Large && elfFs.FileHdr.Machine == elf.EM_X86_64:
+			b.WriteByte('l')
+		case flag == shfARMPurecode && elfFs.FileHdr.Machine == elf.EM_ARM:
+			b.WriteByte('y')
+		case flag&elf.SHF_MASKOS != 0:
+			b.WriteByte('o')
+			flags &^= elf.SHF_MASKOS
+		case flag&elf.SHF_MASKPROC != 0:
+			b.WriteByte('p')
+			flags &^= elf.SHF_MASKPROC
+		default:
+			b.WriteByte('x')
+		}
+	}
+	return b.String()
+}
+
+// gnuSectionFlagWords spells the flags out for -t. OS and processor bits
+// readelf has no name for are lumped together after the names.
+func gnuSectionFlagWords(elfFs *elfparse.ELFFile, flags elf.SectionFlag) string {
+	words := map[elf.SectionFlag]string{
+		elf.SHF_WRITE: "WRITE", elf.SHF_ALLOC: "ALLOC", elf.SHF_EXECINSTR: "EXEC", elf.SHF_MERGE: "MERGE",
+		elf.SHF_STRINGS: "STRINGS", elf.SHF_INFO_LINK: "INFO LINK", elf.SHF_LINK_ORDER: "LINK ORDER",
+		elf.SHF_OS_NONCONFORMING: "OS NONCONF", elf.SHF_GROUP: "GROUP", elf.SHF_TLS: "TLS",
+		elf.SHF_COMPRESSED: "COMPRESSED", shfExclude: "EXCLUDE",
+	}
+	switch elf.OSABI(elfFs.Ident[elf.EI_OSABI]) {
+	case elf.ELFOSABI_LINUX, elf.ELFOSABI_FREEBSD:
+		words[shfGNURetain] = "GNU_RETAIN"
+		fallthrough
+	case elf.ELFOSABI_NONE:
+		words[shfGNUMbind] = "GNU_MBIND"
+	}
+	if elfFs.FileHdr.Machine == elf.EM_ARM {
+		words[shfARMPurecode] = "ARM_PURECODE"
+	}
+
+	var names []string
+	var osFlags, procFlags, unknown elf.SectionFlag
+	for rest := flags; rest != 0; {
+		flag := rest & -rest
+		rest &^= flag
+
+		switch name, ok := words[flag]; {
+		case ok:
+			names = append(names, name)
+		case flag&elf.SHF_MASKOS != 0:
+			osFlags |= flag
+		case flag&elf.SHF_MASKPROC != 0:
+			procFlags |= flag
+		default:
+			unknown |= flag
+		}
+	}
+
+	w := 16
+	if is32(elfFs) {
+		w = 8
+	}
+	for _, f := range []struct {
+		label string
+		bits  elf.SectionFlag
+	}{{"OS", osFlags}, {"PROC", procFlags}, {"UNKNOWN", unknown}} {
+		if f.bits != 0 {
+			names = append(names, fmt.Sprintf("%s (%0*x)", f.label, w, uint64(f.bits)))
+		}
+	}
+	return fmt.Sprintf("[%0*x]: %s", w, uint64(flags), strings.Join(names, ", "))
+}
+
+func gnuSections(w io.Writer, elfFs *elfparse.ELFFile, quiet, details bool) {
+	sections := elfFs.ElfSections.Section
+	if gnuNoSections(elfFs) {
+		fmt.Fprintf(w, "\nThere are no sections in this file.\n")
+		return
+	}
+
+	if !quiet {
+		fmt.Fprintf(w, "There %s %d section %s, starting at offset %s:\n", plural(len(sections), "is", "are"),
+			len(sections), plural(len(sections), "header", "headers"), cHex(elfFs.Hdr.Shoff))
+	}
+	fmt.Fprintf(w, "\nSection %s:\n", plural(len(sections), "Header", "Headers"))
+	addrTitle := "Address         "
+	if is32(elfFs) {
+		addrTitle = "Addr    "
+	}
+	if details {
+		fmt.Fprintln(w, "  [Nr] Name")
+		fmt.Fprintf(w, "       Type            %s Off    Size   ES   Lk Inf Al\n", addrTitle)
+		fmt.Fprintln(w, "       Flags")
+	} else {
+		fmt.Fprintf(w, "  [Nr] Name              Type            %s Off    Size   ES Flg Lk Inf Al\n", addrTitle)
+	}
+
+	for i, s := range sections {
+		addr := fmt.Sprintf("%016x", s.Addr)
+		if is32(elfFs) {
+			addr = fmt.Sprintf("%08x", s.Addr)
+		}
+		if !details {
+			fmt.Fprintf(w, "  [%2d] %-17s %-15s %s %06x %06x %02x %3s %2d %3d %2d\n", i, elfFs.ElfSections.SectionName[i],
+				gnuSectionType(elfFs, s.Type), addr, s.Off, s.Size, s.Entsize, gnuSectionFlags(elfFs, s.Flags),
+				s.Link, s.Info, s.Addralign)
+			continue
+		}
+
+		fmt.Fprintf(w, "  [%2d] %s\n", i, elfFs.ElfSections.SectionName[i])
+		fmt.Fprintf(w, "       %-15s %s %06x %06x %02x %3d %3d %2d\n", gnuSectionType(elfFs, s.Type), addr, s.Off, s.Size,
+			s.Entsize, s.Link, s.Info, s.Addralign)
+		fmt.Fprintf(w, "       %s\n", gnuSectionFlagWords(elfFs, s.Flags))
+		if s.Flags&elf.SHF_COMPRESSED == 0 {
+			continue
+		}
+		if chdr, ok := elfFs.Compression(uint32(i)); ok {
+			size := fmt.Sprintf("%016x", chdr.Size)
+			if is32(elfFs) {
+				size = fmt.Sprintf("%08x", chdr.Size)
+			}
+			switch chdr.Type {
+			case elf.COMPRESS_ZLIB, elf.COMPRESS_ZSTD:
+				fmt.Fprintf(w, "       %s, %s, %d\n", compressionName(chdr.Type), size, chdr.Addralign)
+			default:
+				fmt.Fprintf(w, "       [<unknown>: 0x%x], %s, %d\n", uint32(chdr.Type), size, chdr.Addralign)
+			}
+		}
+	}
+	if details {
+		return
+	}
+
+	fmt.Fprintln(w, "Key to Flags:")
+	fmt.Fprintln(w, "  W (write), A (alloc), X (execute), M (merge), S (strings), I (info),")
+	fmt.Fprintln(w, "  L (link order), O (extra OS processing required), G (group), T (TLS),")
+	fmt.Fprintln(w, "  C (compressed), x (unknown), o (OS specific), E (exclude),")
+	switch elf.OSABI(elfFs.Ident[elf.EI_OSABI]) {
+	case elf.ELFOSABI_LINUX, elf.ELFOSABI_FREEBSD:
+		fmt.Fprint(w, "  R (retain), D (mbind), ")
+	default:
+		fmt.Fprint(w, "  D (mbind), ")
+	}
+	switch elfFs.FileHdr.Machine {
+	case elf.EM_X86_64:
+		fmt.Fprintln(w, "l (large), p (processor specific)")
+	case elf.EM_ARM:
+		fmt.Fprintln(w, "y (purecode), p (processor specific)")
+	default:
+		fmt.Fprintln(w, "p (processor specific)")
+	}
+}
+
+/* program headers */
+
+var gnuSegmentTypes = map[elf.ProgType]string{
+	elf.PT_NULL:              "NULL",
+	elf.PT_LOAD:              "LOAD",
+	elf.PT_DYNAMIC:           "DYNAMIC",
+	elf.PT_INTERP:            "INTERP",
+	elf.PT_NOTE:              "NOTE",
+	elf.PT_SHLIB:             "SHLIB",
+	elf.PT_PHDR:              "PHDR",
+	elf.PT_TLS:               "TLS",
+	elf.PT_GNU_EH_FRAME:      "GNU_EH_FRAME",
+	elf.PT_GNU_STACK:         "GNU_STACK",
+	elf.PT_GNU_RELRO:         "GNU_RELRO",
+	elf.PT_GNU_PROPERTY:      "GNU_PROPERTY",
+	elfparse.PT_GNU_SFRAME:   "GNU_SFRAME",
+	elf.ProgType(0x65a3dbe5): "OPENBSD_MUTABLE",
+	elf.PT_OPENBSD_RANDOMIZE: "OPENBSD_RANDOMIZE",
+	elf.PT_OPENBSD_WXNEEDED:  "OPENBSD_WXNEEDED",
+	elf.ProgType(0x65a3dbe8): "OPENBSD_NOBTCFI",
+	elf.ProgType(0x65a3dbe9): "OPENBSD_SYSCALLS",
+	elf.PT_OPENBSD_BOOTDATA:  "OPENBSD_BOOTDATA",
+}
+
+var gnuProcSegmentTypes = map[elf.Machine]map[elf.ProgType]string{
+	elf.EM_ARM:     {0x70000001: "EXIDX"},
+	elf.EM_AARCH64: {0x70000002: "AARCH64_MEMTAG_MTE"},
+	elf.EM_MIPS:    {0x70000000: "REGINFO", 0x70000001: "RTPROC", 0x70000002: "OPTIONS", 0x70000003: "ABIFLAGS"},
+	elf.EM_PARISC:  {0x70000000: "PARISC_ARCHEXT", 0x70000001: "PARISC_UNWIND"},
+	elf.EM_IA_64:   {0x70000000: "IA_64_ARCHEXT", 0x70000001: "IA_64_UNWIND"},
+	elf.EM_RISCV:   {0x70000003: "RISCV_ATTRIBUTE"},
+	elf.EM_S390:    {0x70000000: "S390_PGSTE"},
+}
+
+func gnuSegmentType(elfFs *elfparse.ELFFile, t elf.ProgType) string {
+	if name, ok := gnuSegmentTypes[t]; ok {
+		return name
+	}
+	switch {
+	case t >= elf.PT_LOPROC && t <= elf.PT_HIPROC:
+		if name, ok := gnuProcSegmentTypes[elfFs.FileHdr.Machine][t]; ok {
+			return name
+		}
+		return "LOPROC+" + cHex(uint64(t-elf.PT_LOPROC))
+	case t >= elf.PT_GNU_MBIND_LO && t <= elf.PT_GNU_MBIND_HI:
+		return "GNU_MBIND+" + cHex(uint64(t-elf.PT_GNU_MBIND_LO))
+	case t >= elf.PT_LOOS && t <= elf.PT_HIOS:
+		return "LOOS+" + cHex(uint64(t-elf.PT_LOOS))
+	}
+	return fmt.Sprintf("<unknown>: %x", uint32(t))
+}
+
+func gnuSegments(w io.Writer, elfFs *elfparse.ELFFile, quiet bool) {
+	progs := elfFs.ProgHeaders
+	if len(progs) == 0 {
+		fmt.Fprintf(w, "\nThere are no program headers in this file.\n")
+		return
+	}
+
+	if !quiet {
+		fmt.Fprintf(w, "\nElf file type is %s\n", gnuFileType(elfFs))
+		fmt.Fprintf(w, "Entry point 0x%x\n", elfFs.Hdr.Entry)
+		fmt.Fprintf(w, "There %s %d program %s, starting at offset %d\n", plural(len(progs), "is", "are"),
+			len(progs), plural(len(progs), "header", "headers"), elfFs.Hdr.Phoff)
+	}
+
+	fmt.Fprintf(w, "\nProgram Headers:\n")
+	if is32(elfFs) {
+		fmt.Fprintln(w, "  Type           Offset   VirtAddr   PhysAddr   FileSiz MemSiz  Flg Align")
+	} else {
+		fmt.Fprintln(w, "  Type           Offset   VirtAddr           PhysAddr           FileSiz  MemSiz   Flg Align")
+	}
+
+	for _, p := range progs {
+		flags := []byte("   ")
+		if p.Flags&elf.PF_R != 0 {
+			flags[0] = 'R'
+		}
+		if p.Flags&elf.PF_W != 0 {
+			flags[1] = 'W'
+		}
+		if p.Flags&elf.PF_X != 0 {
+			flags[2] = 'E'
+		}
+
+		fmt.Fprintf(w, "  %-14.14s ", gnuSegmentType(elfFs, p.Type))
+		if is32(elfFs) {
+			fmt.Fprintf(w, "0x%06x 0x%08x 0x%08x 0x%05x 0x%05x %s %s\n", p.Off, p.Vaddr, p.Paddr, p.Filesz, p.Memsz, flags, cHex(p.Align))
+		} else {
+			fmt.Fprintf(w, "0x%06x 0x%016x 0x%016x 0x%06x 0x%06x %s %s\n", p.Off, p.Vaddr, p.Paddr, p.Filesz, p.Memsz, flags, cHex(p.Align))
+		}
+		if p.Type == elf.PT_INTERP {
+			if interp, ok := elfFs.Interp(); ok {
+				fmt.Fprintf(w, "      [Requesting program interpreter: %s]\n", interp)
+			}
+		}
+	}
+
+	if gnuNoSections(elfFs) {
+		return
+	}
+	fmt.Fprintf(w, "\n Section to Segment mapping:\n")
+	fmt.Fprintf(w, "  Segment Sections...\n")
+	for i := range progs {
+		fmt.Fprintf(w, "   %02d     ", i)
+		for _, sNdx := range elfFs.SegmentSections(i) {
+			fmt.Fprintf(w, "%s ", elfFs.ElfSections.SectionName[sNdx])
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+/* dynamic section */
+
+var gnuDynFlagPrefixes = []string{"DF_GNU_1_", "DF_P1_", "DTF_1_", "DF_1_", "DF_"}
+
+func gnuDynamic(w io.Writer, elfFs *elfparse.ELFFile) {
+	if len(elfFs.Dynamic) == 0 {
+		fmt.Fprintf(w, "\nThere is no dynamic section in this file.\n")
+		return
+	}
+
+	var off uint64
+	for _, p := range elfFs.ProgHeaders {
+		if p.Type == elf.PT_DYNAMIC {
+			off = p.Off
+			break
+		}
+	}
+	if ndx := elfFs.SectionNdx(".dynamic"); off == 0 && ndx != 0 {
+		off = elfFs.ElfSections.Section[ndx].Off
+	}
+
+	n := len(elfFs.Dynamic)
+	fmt.Fprintf(w, "\nDynamic section at offset %s contains %d %s:\n", cHex(off), n, plural(n, "entry", "entries"))
+	fmt.Fprintln(w, "  Tag        Type                         Name/Value")
+
+	pad := 19
+	if is32(elfFs) {
+		pad = 27
+	}
+	for _, entry := range elfFs.Dynamic {
+		name := gnuDynTag(elfFs, entry.Tag)
+		if is32(elfFs) {
+			fmt.Fprintf(w, " 0x%08x", uint64(entry.Tag))
+		} else {
+			fmt.Fprintf(w, " 0x%016x", uint64(entry.Tag))
+		}
+		fmt.Fprintf(w, " (%s)%*s", name, max(pad-len(name), 1), " ")
+		fmt.Fprintln(w, gnuDynValue(elfFs, entry))
+	}
+}
+
+func gnuDynTag(elfFs *elfparse.ELFFile, tag elf.DynTag) string {
+	name := elfFs.DynTagName(tag)
+	switch {
+	case strings.HasPrefix(name, "DT_LOPROC+"):
+		return fmt.Sprintf("Processor Specific: %x", uint64(tag))
+	case strings.HasPrefix(name, "DT_LOOS+"):
+		return fmt.Sprintf("Operating System specific: %x", uint64(tag))
+	case strings.HasPrefix(name, "DT_"):
+		return strings.TrimPrefix(name, "DT_")
+	}
+	return fmt.Sprintf("<unknown>: %x", uint64(tag))
+}
+
+func gnuDynValue(elfFs *elfparse.ELFFile, entry elfparse.DynEntry) string {
+	switch entry.Tag {
+	case elf.DT_NEEDED, elf.DT_SONAME, elf.DT_RPATH, elf.DT_RUNPATH, elf.DT_AUXILIARY,
+		elf.DT_FILTER, elf.DT_CONFIG, elf.DT_DEPAUDIT, elf.DT_AUDIT:
+		str, err := elfFs.DynString(entry)
+		if err != nil {
+			return fmt.Sprintf("0x%x", entry.Val)
+		}
+		switch entry.Tag {
+		case elf.DT_NEEDED:
+			return fmt.Sprintf("Shared library: [%s]", str)
+		case elf.DT_SONAME:
+			return fmt.Sprintf("Library soname: [%s]", str)
+		case elf.DT_RPATH:
+			return fmt.Sprintf("Library rpath: [%s]", str)
+		case elf.DT_RUNPATH:
+			return fmt.Sprintf("Library runpath: [%s]", str)
+		case elf.DT_AUXILIARY:
+			return fmt.Sprintf("Auxiliary library: [%s]", str)
+		case elf.DT_FILTER:
+			return fmt.Sprintf("Filter library: [%s]", str)
+		case elf.DT_CONFIG:
+			return fmt.Sprintf("Configuration file: %s", str)
+		case elf.DT_DEPAUDIT:
+			return fmt.Sprintf("Dependency audit library: %s", str)
+		case elf.DT_AUDIT:
+			return fmt.Sprintf("Audit library: %s", str)
+		}
+
+	case elf.DT_VERDEFNUM, elf.DT_VERNEEDNUM, elf.DT_RELACOUNT, elf.DT_RELCOUNT:
+		return fmt.Sprintf("%d", entry.Val)
+	}
+
+	switch elfFs.DynTagKind(entry.Tag) {
+	case elfparse.DynKindFlags:
+		var names []string
+		for _, name := range elfparse.DynFlagNames(entry.Tag, entry.Val) {
+			for _, prefix := range gnuDynFlagPrefixes {
+				if strings.HasPrefix(name, prefix) {
+					name = strings.TrimPrefix(name, prefix)
+					break
+				}
+			}
+			names = append(names, name)
+		}
+		if entry.Tag == elf.DT_FLAGS {
+			return strings.Join(names, " ")
+		}
+		return "Flags: " + strings.Join(names, " ")
+	case elfparse.DynKindTag:
+		return gnuDynTag(elfFs, elf.DynTag(entry.Val))
+	case elfparse.DynKindSize:
+		return fmt.Sprintf("%d (bytes)", entry.Val)
+	}
+	return fmt.Sprintf("0x%x", entry.Val)
+}
+
+/* relocations */
+
+func gnuRelocType(t uint32, m elf.Machine) (string, bool) {
+	name := resolveRelocType(t, m)
+	if !strings.HasPrefix(name, "R_") || strings.Contains(name, "+") || name == "R_UNKNOWN" {
+		return "", false
+	}
+	/* debug/elf's spelling differs from binutils for a few */
+	name = strings.Replace(name, "_JMP_SLOT", "_JUMP_SLOT", 1)
+	return name, true
+}
+
+// gnuSymbolVersion returns the version suffix readelf appends to dynamic
+// symbol symNdx: "@@V" for the default definition, "@V" for hidden ones
+// and references, the latter followed by " (n)" in symbol tables.
+func gnuSymbolVersion(elfFs *elfparse.ELFFile, symNdx uint32, inTable bool) string {
+	ver, hidden, ok := elfFs.SymbolVersion(symNdx)
+	if !ok {
+		return ""
+	}
+	ndx := elfFs.Versym[symNdx] &^ elfparse.VERSYM_HIDDEN
+	if symNdx < uint32(len(elfFs.DynSymbols)) && elfFs.DynSymbols[symNdx].Shndx != uint16(elf.SHN_UNDEF) {
+		for _, def := range elfFs.Verdef {
+			if def.Ndx == ndx {
+				if hidden {
+					return "@" + ver
+				}
+				return "@@" + ver
+			}
+		}
+	}
+	if inTable {
+		return fmt.Sprintf("@%s (%d)", ver, ndx)
+	}
+	return "@" + ver
+}
+
+func gnuRelocations(w io.Writer, elfFs *elfparse.ELFFile, demangle bool) {
+	/* readelf only reads dynamic relocations through --use-dynamic */
+	if gnuNoSections(elfFs) {
+		for _, tag := range []elf.DynTag{elf.DT_RELASZ, elf.DT_RELSZ, elf.DT_PLTRELSZ, elfparse.DT_RELRSZ} {
+			if size, ok := elfFs.DynValue(tag); ok && size != 0 {
+				fmt.Fprintf(w, "\nThere are no static relocations in this file.\n")
+				fmt.Fprintf(w, "To see the dynamic relocations add --use-dynamic to the command line.\n")
+				return
+			}
+		}
+		fmt.Fprintf(w, "\nThere are no relocations in this file.\n")
+		return
+	}
+
+	found := false
+	for k := range elfFs.ElfSections.Section {
+		/* readelf passes over empty tables without a word */
+		sec := elfFs.ElfSections.Section[k]
+		if sec.Size == 0 {
+			continue
+		}
+		if relr, ok := elfFs.Relrs[uint32(k)]; ok {
+			found = true
+			fmt.Fprintf(w, "\nRelocation section '%s' at offset %s contains %d %s:\n", elfFs.ElfSections.SectionName[k],
+				cHex(sec.Off), relr.Words, plural(relr.Words, "entry", "entries"))
+			fmt.Fprintf(w, "  %d offsets\n", len(relr.Addrs))
+			for _, addr := range relr.Addrs {
+				if is32(elfFs) {
+					fmt.Fprintf(w, "%08x\n", addr)
+				} else {
+					fmt.Fprintf(w, "%016x\n", addr)
+				}
+			}
+			continue
+		}
+
+		rels, ok := elfFs.Rels[uint32(k)]
+		if !ok {
+			continue
+		}
+		found = true
+		isRela := elfparse.HasAddends(sec.Type)
+		isDyn := sec.Link < uint32(len(elfFs.ElfSections.Section)) && elfFs.ElfSections.Section[sec.Link].Type == elf.SHT_DYNSYM
+
+		fmt.Fprintf(w, "\nRelocation section '%s' at offset %s contains %d %s:\n", elfFs.ElfSections.SectionName[k],
+			cHex(sec.Off), len(rels), plural(len(rels), "entry", "entries"))
+		switch {
+		case is32(elfFs) && isRela:
+			fmt.Fprintln(w, " Offset     Info    Type                Sym. Value  Symbol's Name + Addend")
+		case is32(elfFs):
+			fmt.Fprintln(w, " Offset     Info    Type                Sym. Value  Symbol's Name")
+		case isRela:
+			fmt.Fprintln(w, "    Offset             Info             Type               Symbol's Value  Symbol's Name + Addend")
+		default:
+			fmt.Fprintln(w, "    Offset             Info             Type               Symbol's Value  Symbol's Name")
+		}
+
+		for _, rel := range rels {
+			if is32(elfFs) {
+				fmt.Fprintf(w, "%08x  %08x ", rel.Off, rel.Info)
+			} else {
+				fmt.Fprintf(w, "%016x  %016x ", rel.Off, rel.Info)
+			}
+			if name, ok := gnuRelocType(rel.Type, elfFs.FileHdr.Machine); ok {
+				fmt.Fprintf(w, "%-22s", name)
+			} else {
+				fmt.Fprintf(w, "unrecognized: %-7x", rel.Type)
+			}
+
+			if rel.Sym != 0 {
+				symbol, err := elfFs.RelocSymbol(uint32(k), rel)
+				if err != nil {
+					fmt.Fprintf(w, " <corrupt symbol index: %d>\n", rel.Sym)
+					continue
+				}
+				name := displayName(symbol.Name, demangle)
+				if symbol.Type() == elf.SymType(10) { // STT_GNU_IFUNC
+					/* the resolver's address means little, readelf names the function instead */
+					width := 14
+					if is32(elfFs) {
+						width = 8
+					}
+					ver := ""
+					if isDyn {
+						ver = gnuSymbolVersion(elfFs, rel.Sym, false)
+					}
+					fmt.Fprintf(w, " %s%s()%*s", name, ver, max(width+1-len(name), 1), "")
+				} else if is32(elfFs) {
+					fmt.Fprintf(w, " %08x   ", symbol.Value)
+				} else {
+					fmt.Fprintf(w, " %016x ", symbol.Value)
+				}
+				if symbol.NameOff == 0 {
+					name = gnuSectionSymbolName(elfFs, symbol)
+				} else if isDyn {
+					name += gnuSymbolVersion(elfFs, rel.Sym, false)
+				}
+				fmt.Fprint(w, name)
+				if isRela {
+					if rel.Addend < 0 {
+						fmt.Fprintf(w, " - %x", uint64(-rel.Addend))
+					} else {
+						fmt.Fprintf(w, " + %x", rel.Addend)
+					}
+				}
+			} else if isRela {
+				width := 20
+				if is32(elfFs) {
+					width = 12
+				}
+				fmt.Fprintf(w, "%*s", width, "")
+				if rel.Addend < 0 {
+					fmt.Fprintf(w, "-%x", uint64(-rel.Addend))
+				} else {
+					fmt.Fprintf(w, "%x", rel.Addend)
+				}
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	if !found {
+		fmt.Fprintf(w, "\nThere are no relocations in this file.\n")
+	}
+}
+
+// gnuSectionSymbolName is what readelf prints for a nameless symbol,
+// the name of its section when it is a section symbol.
+func gnuSectionSymbolName(elfFs *elfparse.ELFFile, sym elfparse.Symbol) string {
+	if sym.Type() != elf.STT_SECTION {
+		return ""
+	}
+	switch elf.SectionIndex(sym.Shndx) {
+	case elf.SHN_ABS:
+		return "ABS"
+	case elf.SHN_COMMON:
+		return "COM"
+	}
+	reserved := sym.Shndx >= uint16(elf.SHN_LORESERVE) && elf.SectionIndex(sym.Shndx) != elf.SHN_XINDEX
+	if !reserved && sym.Section < uint32(len(elfFs.ElfSections.SectionName)) {
+		return elfFs.ElfSections.SectionName[sym.Section]
+	}
+	return fmt.Sprintf("<section 0x%x>", sym.Section)
+}
+
+/* symbol tables */
+
+func gnuSymType(elfFs *elfparse.ELFFile, t elf.SymType) string {
+	osabi := elf.OSABI(elfFs.Ident[elf.EI_OSABI])
+	switch {
+	case t <= elf.STT_TLS:
+		return strings.TrimPrefix(t.String(), "STT_")
+	case t == 10 && (osabi == elf.ELFOSABI_NONE || osabi == elf.ELFOSABI_LINUX || osabi == elf.ELFOSABI_FREEBSD):
+		return "IFUNC"
+	case t >= elf.STT_LOPROC && t <= elf.STT_HIPROC:
+		return fmt.Sprintf("<processor specific>: %d", t)
+	case t >= elf.STT_LOOS && t <= elf.STT_HIOS:
+		return fmt.Sprintf("<OS specific>: %d", t)
+	}
+	return fmt.Sprintf("<unknown>: %d", t)
+}
+
+func gnuSymBind(elfFs *elfparse.ELFFile, b elf.SymBind) string {
+	osabi := elf.OSABI(elfFs.Ident[elf.EI_OSABI])
+	switch {
+	case b <= elf.STB_WEAK:
+		return strings.TrimPrefix(b.String(), "STB_")
+	case b == 10 && (osabi == elf.ELFOSABI_NONE || osabi == elf.ELFOSABI_LINUX || osabi == elf.ELFOSABI_FREEBSD):
+		return "UNIQUE"
+	case b >= elf.STB_LOPROC && b <= elf.STB_HIPROC:
+		return fmt.Sprintf("<processor specific>: %d", b)
+	case b >= elf.STB_LOOS && b <= elf.STB_HIOS:
+		return fmt.Sprintf("<OS specific>: %d", b)
+	}
+	return fmt.Sprintf("<unknown>: %d", b)
+}
+
+func gnuSymNdx(elfFs *elfparse.ELFFile, sym elfparse.Symbol) string {
+	shndx := sym.Shndx
+	switch ndx := elf.SectionIndex(shndx); {
+	case ndx == elf.SHN_XINDEX:
+		/* the real index from SHT_SYMTAB_SHNDX, which can be anything */
+		if n := elfFs.Hdr.SectionCount; n != 0 && sym.Section >= n {
+			return fmt.Sprintf("bad section index[%3d]", sym.Section)
+		}
+		return fmt.Sprintf("%3d", sym.Section)
+	case ndx == elf.SHN_UNDEF:
+		return "UND"
+	case ndx == elf.SHN_ABS:
+		return "ABS"
+	case ndx == elf.SHN_COMMON:
+		return "COM"
+	case ndx == 0xff02 && elfFs.FileHdr.Machine == elf.EM_X86_64:
+		return "LARGE_COM"
+	case ndx >= elf.SHN_LOPROC && ndx <= elf.SHN_HIPROC:
+		return fmt.Sprintf("PRC[0x%04x]", shndx)
+	case ndx >= elf.SHN_LOOS && ndx <= elf.SHN_HIOS:
+		return fmt.Sprintf("OS [0x%04x]", shndx)
+	case ndx >= elf.SHN_LORESERVE:
+		return fmt.Sprintf("RSV[0x%04x]", shndx)
+	case elfFs.Hdr.SectionCount != 0 && uint32(shndx) >= elfFs.Hdr.SectionCount:
+		return fmt.Sprintf("bad section index[%3d]", shndx)
+	}
+	return fmt.Sprintf("%3d", shndx)
+}
+
+func gnuSymbols(w io.Writer, elfFs *elfparse.ELFFile, q elfparse.SymbolQuery, demangle bool) {
+	if gnuNoSections(elfFs) {
+		fmt.Fprintf(w, "\nDynamic symbol information is not available for displaying symbols.\n")
+		return
+	}
+
+	/* the library loads the first table of each type, whatever it is called */
+	first := func(t elf.SectionType) int {
+		if ndx := elfFs.SectionsByType(t); len(ndx) > 0 {
+			return int(ndx[0])
+		}
+		return -1
+	}
+	dynsym, symtab := first(elf.SHT_DYNSYM), first(elf.SHT_SYMTAB)
+	for k, sec := range elfFs.ElfSections.Section {
+		var symType int
+		switch k {
+		case dynsym:
+			symType = elfparse.DynSym
+		case symtab:
+			symType = elfparse.Sym
+		default:
+			continue
+		}
+
+		n := len(elfFs.SymbolTable(symType))
+		fmt.Fprintf(w, "\nSymbol table '%s' contains %d %s:\n", elfFs.ElfSections.SectionName[k], n, plural(n, "entry", "entries"))
+		if is32(elfFs) {
+			fmt.Fprintln(w, "   Num:    Value  Size Type    Bind   Vis      Ndx Name")
+		} else {
+			fmt.Fprintln(w, "   Num:    Value          Size Type    Bind   Vis      Ndx Name")
+		}
+
+		for _, sym := range elfFs.QuerySymbols(symType, q) {
+			fmt.Fprintf(w, "%6d: ", sym.Index)
+			if is32(elfFs) {
+				fmt.Fprintf(w, "%08x ", sym.Value)
+			} else {
+				fmt.Fprintf(w, "%016x ", sym.Value)
+			}
+			if sym.Size <= 99999 {
+				fmt.Fprintf(w, "%5d", sym.Size)
+			} else {
+				fmt.Fprintf(w, "%#x", sym.Size)
+			}
+			fmt.Fprintf(w, " %-7s %-6s %-7s", gnuSymType(elfFs, sym.Type()), gnuSymBind(elfFs, sym.Bind()),
+				strings.TrimPrefix(sym.Visibility().String(), "STV_"))
+			if other := sym.Other &^ 3; other != 0 {
+				fmt.Fprintf(w, " [<other>: %x] ", other)
+			}
+			fmt.Fprintf(w, " %4s ", gnuSymNdx(elfFs, sym))
+
+			name := displayName(sym.Name, demangle)
+			if sym.NameOff == 0 {
+				name = gnuSectionSymbolName(elfFs, sym)
+			} else if ver, _, _ := elfFs.SymbolVersion(sym.Index); sec.Type == elf.SHT_DYNSYM &&
+				!(elf.SectionIndex(sym.Shndx) == elf.SHN_ABS && ver == sym.Name) {
+				/* the symbol a version definition itself provides goes bare */
+				name += gnuSymbolVersion(elfFs, sym.Index, true)
+			}
+			fmt.Fprintln(w, name)
+		}
+	}
+}
+
+// gnuDumps prints the -x and -p requests the way readelf walks them: by
+// section, each once, its hex dump before its string dump. A name asks
+// for every section of that name.
+func gnuDumps(w io.Writer, elfFs *elfparse.ELFFile, dumps []dump) {
+	hex, str := map[uint32]bool{}, map[uint32]bool{}
+	for _, d := range dumps {
+		want := hex
+		if d.strings {
+			want = str
+		}
+		found := false
+		if ndx, err := strconv.ParseUint(d.section, 10, 32); err == nil {
+			found = ndx < uint64(len(elfFs.ElfSections.Section))
+			want[uint32(ndx)] = found
+		} else {
+			for ndx, name := range elfFs.ElfSections.SectionName {
+				if name == d.section {
+					want[uint32(ndx)], found = true, true
+				}
+			}
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "warning: section '%s' was not dumped because it does not exist\n", d.section)
+		}
+	}
+
+	for ndx := range elfFs.ElfSections.Section {
+		if hex[uint32(ndx)] {
+			dumpSection(w, elfFs, uint32(ndx), false)
+		}
+		if str[uint32(ndx)] {
+			dumpSection(w, elfFs, uint32(ndx), true)
+		}
+	}
+}
+
+func gnuHistograms(w io.Writer, elfFs *elfparse.ELFFile) {
+	for _, h := range hashTables(elfFs) {
+		if len(h.Buckets) == 0 || h.GNU && !slices.ContainsFunc(h.Buckets, func(b uint32) bool { return b != 0 }) {
+			/* readelf drops a .gnu.hash without a single chain */
+			continue
+		}
+		name := ""
+		if h.GNU {
+			name = "`.gnu.hash' "
+		}
+		fmt.Fprintf(w, "\nHistogram for %sbucket list length (total of %d %s):\n", name, len(h.Buckets), plural(len(h.Buckets), "bucket", "buckets"))
+		printHistogram(w, h)
+	}
+}
+
+/* version sections */
+
+// gnuVersions prints the version sections in section header order, as
+// readelf does. The tables are the ones elfparse read, from the first
+// section of each type.
+func gnuVersions(w io.Writer, elfFs *elfparse.ELFFile) {
+	found := false
+	if !gnuNoSections(elfFs) {
+		for i, s := range elfFs.ElfSections.Section {
+			switch {
+			case s.Synthetic || elfFs.SectionsByType(s.Type)[0] != uint32(i):
+				continue
+			case s.Type == elf.SHT_GNU_VERDEF:
+				gnuVerdef(w, elfFs, s, i)
+			case s.Type == elf.SHT_GNU_VERNEED:
+				gnuVerneed(w, elfFs, s, i)
+			case s.Type == elf.SHT_GNU_VERSYM:
+				gnuVersym(w, elfFs, s, i)
+			default:
+				continue
+			}
+			found = true
+		}
+	}
+	if !found {
+		fmt.Fprintf(w, "\nNo version information found in this file.\n")
+	}
+}
+
+func gnuVersionSection(w io.Writer, elfFs *elfparse.ELFFile, s elfparse.Section, ndx int, title string, n uint64) {
+	link := "<corrupt>"
+	if s.Link < uint32(len(elfFs.ElfSections.SectionName)) {
+		link = elfFs.ElfSections.SectionName[s.Link]
+	}
+	fmt.Fprintf(w, "\n%s section '%s' contains %d %s:\n", title, elfFs.ElfSections.SectionName[ndx], n, plural(int(n), "entry", "entries"))
+	fmt.Fprintf(w, " Addr: 0x%016x  Offset: 0x%08x  Link: %d (%s)\n", s.Addr, s.Off, s.Link, link)
+}
+
+// gnuVerFlags is verFlags with the bits readelf doesn't know lumped into
+// one "<unknown>".
+func gnuVerFlags(flags uint16) string {
+	known := flags & (elfparse.VER_FLG_BASE | elfparse.VER_FLG_WEAK | elfparse.VER_FLG_INFO)
+	switch {
+	case flags == known:
+		return verFlags(flags)
+	case known == 0:
+		return "<unknown>"
+	}
+	return verFlags(known) + " | <unknown>"
+}
+
+func gnuVerdef(w io.Writer, elfFs *elfparse.ELFFile, s elfparse.Section, ndx int) {
+	gnuVersionSection(w, elfFs, s, ndx, "Version definition", uint64(s.Info))
+	for _, def := range elfFs.Verdef {
+		name := ""
+		if len(def.Names) > 0 {
+			name = def.Names[0]
+		}
+		fmt.Fprintf(w, "  %s: Rev: %d  Flags: %s  Index: %d  Cnt: %d  Name: %s\n", gnuVerOff(def.Off), def.Version,
+			gnuVerFlags(def.Flags), def.Ndx, len(def.Names), name)
+		for i := 1; i < len(def.Names); i++ {
+			fmt.Fprintf(w, "  %s: Parent %d: %s\n", gnuVerOff(def.NameOff[i]), i, def.Names[i])
+		}
+	}
+}
+
+func gnuVerneed(w io.Writer, elfFs *elfparse.ELFFile, s elfparse.Section, ndx int) {
+	gnuVersionSection(w, elfFs, s, ndx, "Version needs", uint64(s.Info))
+	for _, need := range elfFs.Verneed {
+		fmt.Fprintf(w, "  %s: Version: %d  File: %s  Cnt: %d\n", gnuVerOff(need.Off), need.Version, need.File, len(need.Aux))
+		for _, aux := range need.Aux {
+			fmt.Fprintf(w, "  %s:   Name: %s  Flags: %s  Version: %d\n", gnuVerOff(aux.Off), aux.Name, gnuVerFlags(aux.Flags), aux.Other)
+		}
+	}
+}
+
+// gnuVerOff is C's "%#06x", which leaves the 0x off 0.
+func gnuVerOff(off uint64) string {
+	if off == 0 {
+		return "000000"
+	}
+	return fmt.Sprintf("0x%04x", off)
+}
+
+func gnuVersym(w io.Writer, elfFs *elfparse.ELFFile, s elfparse.Section, ndx int) {
+	versym := elfFs.Versym
+	gnuVersionSection(w, elfFs, s, ndx, "Version symbols", uint64(len(versym)))
+
+	/* readelf counts the symbols in sh_link, whatever its type */
+	var nsyms uint64
+	if sections := elfFs.ElfSections.Section; s.Link < uint32(len(sections)) && sections[s.Link].Entsize != 0 {
+		nsyms = sections[s.Link].Size / sections[s.Link].Entsize
+	}
+	for i, v := range versym {
+		if i%4 == 0 {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "  %03x:", i)
+		}
+		switch v {
+		case elfparse.VER_NDX_LOCAL:
+			fmt.Fprint(w, "   0 (*local*)    ")
+			continue
+		case elfparse.VER_NDX_GLOBAL:
+			fmt.Fprint(w, "   1 (*global*)   ")
+			continue
+		}
+
+		hidden := ' '
+		if v&elfparse.VERSYM_HIDDEN != 0 {
+			hidden = 'h'
+		}
+		cell := fmt.Sprintf("%4x%c", v&^elfparse.VERSYM_HIDDEN, hidden)
+		if uint64(i) >= nsyms {
+			fmt.Fprint(w, cell)
+			continue
+		}
+
+		/*
+		 * readelf looks the index up among the needed versions, hidden
+		 * bit included, then among the defined ones, and prints every
+		 * match. Names longer than 12 pad by the excess, as a negative
+		 * C field width does.
+		 */
+		var names []string
+		for _, need := range elfFs.Verneed {
+			for _, aux := range need.Aux {
+				if aux.Other == v {
+					names = append(names, aux.Name)
+					break
+				}
+			}
+		}
+		if v != elfparse.VERSYM_HIDDEN|elfparse.VER_NDX_GLOBAL {
+			for _, def := range elfFs.Verdef {
+				if def.Ndx == v&^elfparse.VERSYM_HIDDEN && len(def.Names) > 0 {
+					names = append(names, def.Names[0])
+					break
+				}
+			}
+		}
+		for _, name := range names {
+			cell += fmt.Sprintf("(%s%-*s", name, 12-len(name), ")")
+		}
+		fmt.Fprintf(w, "%-18s", cell)
+	}
+	if len(versym) > 0 {
+		fmt.Fprintln(w)
+	}
+}
+
+/* notes */
+
+func gnuNotes(w io.Writer, elfFs *elfparse.ELFFile) {
+	for _, table := range elfFs.Notes {
+		if table.Name != "" {
+			fmt.Fprintf(w, "\nDisplaying notes found in: %s\n", table.Name)
+		} else {
+			fmt.Fprintf(w, "\nDisplaying notes found at file offset 0x%08x with length 0x%08x:\n", table.Off, table.Size)
+		}
+		fmt.Fprintln(w, "  Owner                Data size \tDescription")
+		for _, note := range table.Notes {
+			fmt.Fprintf(w, "  %-20s 0x%08x\t%s\t%s\n", note.Owner, len(note.Desc), gnuNoteType(elfFs, note),
+				gnuNoteDescription(elfFs, note))
+		}
+	}
+}
+
+/* the types readelf names whatever the owner */
+var gnuGenericNoteTypes = map[uint32]string{
+	1:          "NT_VERSION (version)",
+	2:          "NT_ARCH (architecture)",
+	4:          "GO BUILDID",
+	0xcafe1a7e: "FDO_PACKAGING_METADATA",
+}
+
+// gnuNoteType names the note type. readelf knows the types of GNU notes
+// and of core files by owner, the rest only from its generic list.
+func gnuNoteType(elfFs *elfparse.ELFFile, n elfparse.Note) string {
+	if n.Owner == "GNU" || elfFs.Hdr.Type == elf.ET_CORE && (n.Owner == "CORE" || n.Owner == "LINUX") {
+		return elfFs.NoteTypeName(n)
+	}
+	if n.Owner == "stapsdt" {
+		if n.Type == 3 {
+			return "NT_STAPSDT (SystemTap probe descriptors)"
+		}
+		return fmt.Sprintf("Unknown note type: (0x%08x)", n.Type)
+	}
+	if name, ok := gnuGenericNoteTypes[n.Type]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown note type: (0x%08x)", n.Type)
+}
+
+var gnuABITagOS = []string{"Linux", "Hurd", "Solaris", "FreeBSD", "NetBSD", "Syllable", "NaCl"}
+
+// gnuNoteDescription decodes the notes readelf decodes and dumps the
+// bytes of any other.
+func gnuNoteDescription(elfFs *elfparse.ELFFile, n elfparse.Note) string {
+	switch {
+	case n.Owner == "stapsdt":
+		return gnuStapsdt(elfFs, n.Desc)
+	case n.Owner == "FDO" && n.Type == 0xcafe1a7e && len(n.Desc) > 0:
+		meta, _, _ := bytes.Cut(n.Desc, []byte{0})
+		return "    Packaging Metadata: " + string(meta)
+	case n.Owner != "GNU" && len(n.Desc) == 0:
+		return ""
+	case n.Owner != "GNU":
+		return "   description data: " + gnuHexBytes(n.Desc)
+	}
+
+	bo := elfFs.FileHdr.Endianness
+	switch n.Type {
+	case elfparse.NT_GNU_BUILD_ID, elfparse.NT_GNU_GOLD_VERSION:
+		return "    " + elfFs.NoteDescription(n)[0]
+
+	case elfparse.NT_GNU_ABI_TAG:
+		if len(n.Desc) < 16 {
+			return "    <corrupt GNU_ABI_TAG>"
+		}
+		os := "Unknown"
+		if v := bo.Uint32(n.Desc); v < uint32(len(gnuABITagOS)) {
+			os = gnuABITagOS[v]
+		}
+		return fmt.Sprintf("    OS: %s, ABI: %d.%d.%d", os, bo.Uint32(n.Desc[4:]), bo.Uint32(n.Desc[8:]), bo.Uint32(n.Desc[12:]))
+
+	case elfparse.NT_GNU_HWCAP:
+		if len(n.Desc) < 8 {
+			return "      Hardware Capabilities: "
+		}
+		return fmt.Sprintf("      Hardware Capabilities: num entries: %d, enabled mask: %x", bo.Uint32(n.Desc), bo.Uint32(n.Desc[4:]))
+
+	case elfparse.NT_GNU_PROPERTY_TYPE_0:
+		word := 8
+		if is32(elfFs) {
+			word = 4
+		}
+		if len(n.Desc) < 8 || len(n.Desc)%word != 0 {
+			return fmt.Sprintf("      Properties: <corrupt GNU_PROPERTY_TYPE, size = %s>", cHex(uint64(len(n.Desc))))
+		}
+		props := elfFs.NoteDescription(n)
+		for i, p := range props {
+			p = strings.TrimPrefix(strings.TrimSpace(p), "Properties: ")
+			if p == "no copy on protected" {
+				p += " " // readelf leaves its separator in
+			}
+			props[i] = p
+		}
+		return "      Properties: " + strings.Join(props, ", ")
+	}
+	return "    Description data: " + gnuHexBytes(n.Desc)
+}
+
+// gnuStapsdt decodes a SystemTap probe: its location, base and semaphore
+// addresses followed by the provider, probe and argument strings.
+func gnuStapsdt(elfFs *elfparse.ELFFile, desc []byte) string {
+	const corrupt = "  <corrupt - note is too small>"
+	bo := elfFs.FileHdr.Endianness
+	word, addr := 8, func(b []byte) string { return fmt.Sprintf("0x%016x", bo.Uint64(b)) }
+	if is32(elfFs) {
+		word, addr = 4, func(b []byte) string { return fmt.Sprintf("0x%08x", bo.Uint32(b)) }
+	}
+	if len(desc) < 3*word {
+		return corrupt
+	}
+
+	var strs [3]string
+	rest := desc[3*word:]
+	for i := range strs {
+		str, after, ok := bytes.Cut(rest, []byte{0})
+		if !ok {
+			return corrupt
+		}
+		strs[i], rest = string(str), after
+	}
+	return fmt.Sprintf("    Provider: %s\n    Name: %s\n    Location: %s, Base: %s, Semaphore: %s\n    Arguments: %s",
+		strs[0], strs[1], addr(desc), addr(desc[word:]), addr(desc[2*word:]), strs[2])
+}
+
+func gnuHexBytes(b []byte) string {
+	var s strings.Builder
+	for _, c := range b {
+		fmt.Fprintf(&s, "%02x ", c)
+	}
+	return s.String()
+}
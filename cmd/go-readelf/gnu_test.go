@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+// testdata/*.golden are "readelf -W -hSlsrdVnI" (binutils 2.40) of the
+// files of the same name in elfparse/testdata.
+func TestGNUMatchesReadelf(t *testing.T) {
+	for _, name := range goldenFiles {
+		t.Run(name, func(t *testing.T) {
+			c, err := parseArgs([]string{"--compat=gnu", "-hSlsrdVnI", "../../elfparse/testdata/" + name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			elfFs, err := elfparse.OpenOptions(c.files[0], c.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer elfFs.Close()
+
+			var buf bytes.Buffer
+			printGNU(&buf, elfFs, c.m)
+			compareGolden(t, buf.String(), "testdata/"+name+".golden")
+		})
+	}
+}
+
+// testdata/z.o.dump.golden is readelf -W of the same requests on
+// elfparse/testdata/z.o, asked for out of section order and .text twice.
+func TestDumpsMatchReadelf(t *testing.T) {
+	c, err := parseArgs([]string{"--compat=gnu", "-W",
+		"-p", ".comment", "-x", ".text", "-p", ".rodata.str1.1", "-x", ".data.rel.ro.local", "-x", "1",
+		"../../elfparse/testdata/z.o"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	elfFs, err := elfparse.OpenOptions(c.files[0], c.opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer elfFs.Close()
+
+	var buf bytes.Buffer
+	printGNU(&buf, elfFs, c.m)
+	compareGolden(t, buf.String(), "testdata/z.o.dump.golden")
+}
+
+// ifunc.so is ifunc.c linked with gcc -shared -fPIC -O2
+// -Wl,--hash-style=both, for its IFUNC and unique symbols and for having
+// both hash tables.
+var goldenFiles = []string{
+	"v.so", "relr.so", "ap.so", "ifunc.so",
+	"seg-i386", "seg-x86_64", "seg-mips32be", "seg-mips64be", "seg-s390x", "seg-arm", "seg-aarch64",
+}
+
+// compareGolden fails at the first line of got that isn't the one of file.
+func compareGolden(t *testing.T, got string, file string) {
+	t.Helper()
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotLines, wantLines := strings.Split(got, "\n"), strings.Split(string(want), "\n")
+	for i := 0; i < min(len(gotLines), len(wantLines)); i++ {
+		if gotLines[i] != wantLines[i] {
+			t.Fatalf("line %d:\n got %q\nwant %q", i+1, gotLines[i], wantLines[i])
+		}
+	}
+	if len(gotLines) != len(wantLines) {
+		t.Fatalf("%d lines, want %d", len(gotLines), len(wantLines))
+	}
+}
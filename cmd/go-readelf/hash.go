@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+// hashTables are the hash tables of the file, SysV first like readelf.
+func hashTables(elfFs *elfparse.ELFFile) []*elfparse.HashTable {
+	var tables []*elfparse.HashTable
+	for _, h := range []*elfparse.HashTable{elfFs.Hash, elfFs.GNUHash} {
+		if h != nil {
+			tables = append(tables, h)
+		}
+	}
+	return tables
+}
+
+// histogram counts the buckets of every chain length, counts[n] is the
+// number of chains n symbols long; total is the number of symbols on all
+// of them.
+func histogram(h *elfparse.HashTable) (counts []int, total int) {
+	lengths := h.ChainLengths()
+	longest := 0
+	for _, l := range lengths {
+		longest = max(longest, l)
+		total += l
+	}
+	counts = make([]int, longest+1)
+	for _, l := range lengths {
+		counts[l]++
+	}
+	return counts, total
+}
+
+// printHistogram prints the bucket list length table of readelf -I. The
+// coverage column is the share of the symbols found within that many
+// steps.
+func printHistogram(w io.Writer, h *elfparse.HashTable) {
+	counts, total := histogram(h)
+	nbuckets := float64(len(h.Buckets))
+	fmt.Fprintln(w, " Length  Number     % of total  Coverage")
+	fmt.Fprintf(w, "      0  %-10d (%5.1f%%)\n", counts[0], float64(counts[0])*100/nbuckets)
+	covered := 0
+	for n := 1; n < len(counts); n++ {
+		covered += counts[n] * n
+		fmt.Fprintf(w, "%7d  %-10d (%5.1f%%)    %5.1f%%\n", n, counts[n], float64(counts[n])*100/nbuckets, float64(covered)*100/float64(total))
+	}
+}
+
+func hashKind(h *elfparse.HashTable) string {
+	if h.GNU {
+		return "GNU"
+	}
+	return "SysV"
+}
+
+func printHashTables(elfFs *elfparse.ELFFile, demangle bool) {
+	tables := hashTables(elfFs)
+	if len(tables) == 0 {
+		fmt.Println("There are no hash tables in this file.")
+		return
+	}
+
+	for _, h := range tables {
+		fmt.Printf("\n%s hash table %s contains %d buckets", hashKind(h), elfFs.ElfSections.SectionName[h.Section], len(h.Buckets))
+		if h.GNU {
+			fmt.Printf(", %d bloom words shifted by %d, symbols from %d\n", len(h.Bloom), h.BloomShift, h.SymOffset)
+		} else {
+			fmt.Printf(" and %d chains\n", len(h.Chains))
+		}
+		if len(h.Buckets) > 0 {
+			printHistogram(os.Stdout, h)
+		}
+
+		problems := elfFs.CheckHashTable(h)
+		if len(problems) == 0 {
+			fmt.Println("Every exported symbol of .dynsym can be looked up")
+			continue
+		}
+		fmt.Printf("%d exported %s of .dynsym can't be looked up:\n", len(problems), plural(len(problems), "symbol", "symbols"))
+		for _, p := range problems {
+			fmt.Printf("  %-5d %s: %s\n", p.Symbol, displayName(p.Name, demangle), p.Reason)
+		}
+	}
+}
+
+// warnHashProblems reports the lookup failures on stderr, for the
+// layouts that copy another tool's output and have no room for them.
+func warnHashProblems(elfFs *elfparse.ELFFile, demangle bool) {
+	for _, h := range hashTables(elfFs) {
+		for _, p := range elfFs.CheckHashTable(h) {
+			fmt.Fprintf(os.Stderr, "warning: %s: symbol %d (%s) can't be looked up: %s\n",
+				elfFs.ElfSections.SectionName[h.Section], p.Symbol, displayName(p.Name, demangle), p.Reason)
+		}
+	}
+}
+
+// symbolLookups runs the --lookup queries, "name" or "name@version"
+// ("name@@version" works too).
+func symbolLookups(elfFs *elfparse.ELFFile, queries []string) ([]elfparse.SymbolLookup, error) {
+	var lookups []elfparse.SymbolLookup
+	for _, query := range queries {
+		name, version, _ := strings.Cut(query, "@")
+		l, err := elfFs.LookupSymbol(name, strings.TrimPrefix(version, "@"))
+		if err != nil {
+			return nil, err
+		}
+		lookups = append(lookups, l)
+	}
+	return lookups, nil
+}
+
+// printLookups prints every step of the --lookup queries, like
+//
+//	Looking up realpath in .gnu.hash: hash 0xf9e3e036, bucket 269
+//	  [827] realpath
+//	found [827] realpath@@GLIBC_2.3 = 0x3d560 (STT_FUNC STB_GLOBAL, .text, size 1966)
+func printLookups(elfFs *elfparse.ELFFile, m modes) {
+	if len(m.lookups) == 0 {
+		return
+	}
+	lookups, err := symbolLookups(elfFs, m.lookups)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: --lookup: %v\n", err)
+		return
+	}
+
+	for _, l := range lookups {
+		query := l.Name
+		if l.Version != "" {
+			query += "@" + l.Version
+		}
+		fmt.Printf("\nLooking up %s in %s: hash 0x%08x", displayName(query, m.demangle), elfFs.ElfSections.SectionName[l.Table.Section], l.Hash)
+		if l.Bucket >= 0 {
+			fmt.Printf(", bucket %d", l.Bucket)
+		}
+		fmt.Println()
+		for _, c := range l.Candidates {
+			name := displayName(c.Symbol.Name, m.demangle)
+			if c.Reason == "" {
+				fmt.Printf("  [%d] %s\n", c.Symbol.Index, name)
+			} else {
+				fmt.Printf("  [%d] %s: %s\n", c.Symbol.Index, name, c.Reason)
+			}
+		}
+
+		if !l.Found {
+			fmt.Printf("not found: %s\n", l.Reason)
+			continue
+		}
+		sym := l.Symbol
+		section := sym.SectionName
+		if section == "" {
+			section = fmt.Sprintf("section %d", sym.Section)
+		}
+		fmt.Printf("found [%d] %s = 0x%x (%s %s, %s, size %d)\n", sym.Index, elfFs.VersionedName(sym.Index, displayName(sym.Name, m.demangle)),
+			sym.Value, sym.Type(), sym.Bind(), section, sym.Size)
+	}
+}
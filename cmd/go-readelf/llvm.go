@@ -0,0 +1,1190 @@
+package main
+
+import (
+	"debug/elf"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+// The --compat=llvm renderer prints the nested blocks of
+// "llvm-readobj --elf-output-style=LLVM", for FileCheck based tests.
+
+// llvmPrinter keeps track of the block nesting; llvm-readobj indents
+// every level by two spaces.
+type llvmPrinter struct {
+	w        io.Writer
+	indent   int
+	demangle bool
+}
+
+func (p *llvmPrinter) line(format string, args ...interface{}) {
+	fmt.Fprintf(p.w, "%s%s\n", strings.Repeat("  ", p.indent), fmt.Sprintf(format, args...))
+}
+
+func (p *llvmPrinter) open(format string, args ...interface{}) {
+	p.line(format, args...)
+	p.indent++
+}
+
+func (p *llvmPrinter) close(end string) {
+	p.indent--
+	p.line("%s", end)
+}
+
+// llvmFlag is an entry of the flag lists llvm-readobj decodes. Entries
+// covered by one of the masks are enumerated values, not single bits.
+type llvmFlag struct {
+	name  string
+	value uint64
+}
+
+func (p *llvmPrinter) flags(label string, val uint64, names []llvmFlag, masks ...uint64) {
+	var set []llvmFlag
+	for _, f := range names {
+		if f.value == 0 {
+			continue
+		}
+		var mask uint64
+		for _, m := range masks {
+			if f.value&m != 0 {
+				mask = m
+				break
+			}
+		}
+		if (mask == 0 && val&f.value == f.value) || (mask != 0 && val&mask == f.value) {
+			set = append(set, f)
+		}
+	}
+	sort.SliceStable(set, func(i, j int) bool { return set[i].name < set[j].name })
+
+	p.open("%s [ (0x%X)", label, val)
+	for _, f := range set {
+		p.line("%s (0x%X)", f.name, f.value)
+	}
+	p.close("]")
+}
+
+// bits is a flags block without names, each set bit listed on its own.
+func (p *llvmPrinter) bits(label string, val uint64) {
+	p.open("%s [ (0x%X)", label, val)
+	for bit := uint64(1); bit != 0 && bit <= val; bit <<= 1 {
+		if val&bit != 0 {
+			p.line("0x%X", bit)
+		}
+	}
+	p.close("]")
+}
+
+// binary is llvm-readobj's binary block: 16 bytes a row in groups of
+// four, followed by their printable characters.
+func (p *llvmPrinter) binary(label string, data []byte) {
+	p.open("%s (", label)
+	for off := 0; off < len(data); off += 16 {
+		var hex, ascii strings.Builder
+		for i, b := range data[off:min(off+16, len(data))] {
+			if i > 0 && i%4 == 0 {
+				hex.WriteByte(' ')
+			}
+			fmt.Fprintf(&hex, "%02X", b)
+			if b < ' ' || b > '~' {
+				b = '.'
+			}
+			ascii.WriteByte(b)
+		}
+		p.line("%04X: %-35s  |%s|", off, hex.String(), ascii.String())
+	}
+	p.close(")")
+}
+
+func printLLVM(w io.Writer, elfFs *elfparse.ELFFile, file string, m modes) {
+	p := &llvmPrinter{w: w, demangle: m.demangle}
+	fmt.Fprintln(w)
+	p.line("File: %s", file)
+	p.line("Format: %s", llvmFormat(elfFs))
+	p.line("Arch: %s", llvmArch(elfFs))
+	if is32(elfFs) {
+		p.line("AddressSize: 32bit")
+	} else {
+		p.line("AddressSize: 64bit")
+	}
+	loadName := "<Not found>"
+	for _, entry := range elfFs.Dynamic {
+		if entry.Tag == elf.DT_SONAME {
+			if str, err := elfFs.DynString(entry); err == nil {
+				loadName = str
+			}
+		}
+	}
+	p.line("LoadName: %s", loadName)
+
+	if m.header {
+		llvmHeader(p, elfFs)
+	}
+	if m.sections {
+		llvmSections(p, elfFs)
+	}
+	if m.progHeaders {
+		llvmSegments(p, elfFs)
+	}
+	if m.dynamic {
+		llvmDynamic(p, elfFs)
+	}
+	switch {
+	case m.relocSummary:
+		printRelocSummary(elfFs)
+	case m.relocations:
+		llvmRelocations(p, elfFs)
+	}
+	if m.symbols {
+		llvmSymbols(p, elfFs, elf.SHT_SYMTAB, m.symQuery)
+		llvmSymbols(p, elfFs, elf.SHT_DYNSYM, m.symQuery)
+	}
+	if m.histogram {
+		llvmHashTables(p, elfFs)
+		warnHashProblems(elfFs, m.demangle)
+	}
+	if m.versions {
+		llvmVersions(p, elfFs)
+	}
+	if m.notes {
+		llvmNotes(p, elfFs)
+	}
+	printDumps(p.w, elfFs, m.dumps)
+	printAddr2Sym(elfFs, m)
+	printLookups(elfFs, m)
+}
+
+/* file header */
+
+func llvmFormat(elfFs *elfparse.ELFFile) string {
+	little := elfFs.Ident[elf.EI_DATA] == byte(elf.ELFDATA2LSB)
+	endian := func(le, be string) string {
+		if little {
+			return le
+		}
+		return be
+	}
+
+	if is32(elfFs) {
+		switch elfFs.FileHdr.Machine {
+		case elf.EM_386:
+			return "elf32-i386"
+		case elf.Machine(6):
+			return "elf32-iamcu"
+		case elf.EM_X86_64:
+			return "elf32-x86-64"
+		case elf.EM_ARM:
+			return endian("elf32-littlearm", "elf32-bigarm")
+		case elf.EM_AVR:
+			return "elf32-avr"
+		case elf.EM_MIPS:
+			return "elf32-mips"
+		case elf.EM_MSP430:
+			return "elf32-msp430"
+		case elf.EM_PPC:
+			return endian("elf32-powerpcle", "elf32-powerpc")
+		case elf.EM_RISCV:
+			return "elf32-littleriscv"
+		case elf.EM_SPARC, elf.EM_SPARC32PLUS:
+			return "elf32-sparc"
+		case elf.EM_LOONGARCH:
+			return "elf32-loongarch"
+		}
+		return "elf32-unknown"
+	}
+
+	switch elfFs.FileHdr.Machine {
+	case elf.EM_386:
+		return "elf64-i386"
+	case elf.EM_X86_64:
+		return "elf64-x86-64"
+	case elf.EM_AARCH64:
+		return endian("elf64-littleaarch64", "elf64-bigaarch64")
+	case elf.EM_PPC64:
+		return endian("elf64-powerpcle", "elf64-powerpc")
+	case elf.EM_RISCV:
+		return "elf64-littleriscv"
+	case elf.EM_S390:
+		return "elf64-s390"
+	case elf.EM_SPARCV9:
+		return "elf64-sparc"
+	case elf.EM_MIPS:
+		return "elf64-mips"
+	case elf.EM_AMDGPU:
+		return "elf64-amdgpu"
+	case elf.EM_BPF:
+		return "elf64-bpf"
+	case elf.EM_LOONGARCH:
+		return "elf64-loongarch"
+	}
+	return "elf64-unknown"
+}
+
+func llvmArch(elfFs *elfparse.ELFFile) string {
+	little := elfFs.Ident[elf.EI_DATA] == byte(elf.ELFDATA2LSB)
+	endian := func(le, be string) string {
+		if little {
+			return le
+		}
+		return be
+	}
+
+	switch elfFs.FileHdr.Machine {
+	case elf.EM_386, elf.Machine(6):
+		return "i386"
+	case elf.EM_X86_64:
+		if is32(elfFs) {
+			return "i386"
+		}
+		return "x86_64"
+	case elf.EM_ARM:
+		return endian("arm", "armeb")
+	case elf.EM_AARCH64:
+		return endian("aarch64", "aarch64_be")
+	case elf.EM_MIPS:
+		if is32(elfFs) {
+			return endian("mipsel", "mips")
+		}
+		return endian("mips64el", "mips64")
+	case elf.EM_PPC:
+		return endian("ppcle", "ppc")
+	case elf.EM_PPC64:
+		return endian("ppc64le", "ppc64")
+	case elf.EM_RISCV:
+		if is32(elfFs) {
+			return "riscv32"
+		}
+		return "riscv64"
+	case elf.EM_S390:
+		return "s390x"
+	case elf.EM_SPARC, elf.EM_SPARC32PLUS:
+		return "sparc"
+	case elf.EM_SPARCV9:
+		return "sparcv9"
+	case elf.EM_BPF:
+		return endian("bpfel", "bpfeb")
+	case elf.EM_LOONGARCH:
+		if is32(elfFs) {
+			return "loongarch32"
+		}
+		return "loongarch64"
+	case elf.EM_AVR:
+		return "avr"
+	case elf.EM_MSP430:
+		return "msp430"
+	}
+	return "unknown"
+}
+
+var llvmOSABI = map[elf.OSABI]string{
+	elf.ELFOSABI_NONE:       "SystemV",
+	elf.ELFOSABI_HPUX:       "HPUX",
+	elf.ELFOSABI_NETBSD:     "NetBSD",
+	elf.ELFOSABI_LINUX:      "GNU/Linux",
+	elf.ELFOSABI_HURD:       "GNU/Hurd",
+	elf.ELFOSABI_SOLARIS:    "Solaris",
+	elf.ELFOSABI_AIX:        "AIX",
+	elf.ELFOSABI_IRIX:       "IRIX",
+	elf.ELFOSABI_FREEBSD:    "FreeBSD",
+	elf.ELFOSABI_TRU64:      "TRU64",
+	elf.ELFOSABI_MODESTO:    "Modesto",
+	elf.ELFOSABI_OPENBSD:    "OpenBSD",
+	elf.ELFOSABI_OPENVMS:    "OpenVMS",
+	elf.ELFOSABI_NSK:        "NSK",
+	elf.ELFOSABI_AROS:       "AROS",
+	elf.ELFOSABI_FENIXOS:    "FenixOS",
+	elf.ELFOSABI_CLOUDABI:   "CloudABI",
+	elf.ELFOSABI_STANDALONE: "Standalone",
+}
+
+var llvmFileTypes = map[elf.Type]string{
+	elf.ET_NONE: "None",
+	elf.ET_REL:  "Relocatable",
+	elf.ET_EXEC: "Executable",
+	elf.ET_DYN:  "SharedObject",
+	elf.ET_CORE: "Core",
+}
+
+var llvmRISCVFlags = []llvmFlag{
+	{"EF_RISCV_RVC", 0x1},
+	{"EF_RISCV_FLOAT_ABI_SOFT", 0x0},
+	{"EF_RISCV_FLOAT_ABI_SINGLE", 0x2},
+	{"EF_RISCV_FLOAT_ABI_DOUBLE", 0x4},
+	{"EF_RISCV_FLOAT_ABI_QUAD", 0x6},
+	{"EF_RISCV_RVE", 0x8},
+	{"EF_RISCV_TSO", 0x10},
+}
+
+var llvmMIPSFlags = []llvmFlag{
+	{"EF_MIPS_NOREORDER", 0x1},
+	{"EF_MIPS_PIC", 0x2},
+	{"EF_MIPS_CPIC", 0x4},
+	{"EF_MIPS_ABI2", 0x20},
+	{"EF_MIPS_32BITMODE", 0x100},
+	{"EF_MIPS_FP64", 0x200},
+	{"EF_MIPS_NAN2008", 0x400},
+	{"EF_MIPS_ABI_O32", 0x1000},
+	{"EF_MIPS_ABI_O64", 0x2000},
+	{"EF_MIPS_ABI_EABI32", 0x3000},
+	{"EF_MIPS_ABI_EABI64", 0x4000},
+	{"EF_MIPS_MICROMIPS", 0x2000000},
+	{"EF_MIPS_ARCH_ASE_M16", 0x4000000},
+	{"EF_MIPS_ARCH_ASE_MDMX", 0x8000000},
+	{"EF_MIPS_ARCH_1", 0x0},
+	{"EF_MIPS_ARCH_2", 0x10000000},
+	{"EF_MIPS_ARCH_3", 0x20000000},
+	{"EF_MIPS_ARCH_4", 0x30000000},
+	{"EF_MIPS_ARCH_5", 0x40000000},
+	{"EF_MIPS_ARCH_32", 0x50000000},
+	{"EF_MIPS_ARCH_64", 0x60000000},
+	{"EF_MIPS_ARCH_32R2", 0x70000000},
+	{"EF_MIPS_ARCH_64R2", 0x80000000},
+	{"EF_MIPS_ARCH_32R6", 0x90000000},
+	{"EF_MIPS_ARCH_64R6", 0xa0000000},
+}
+
+func llvmHeader(p *llvmPrinter, elfFs *elfparse.ELFFile) {
+	h := elfFs.Hdr
+	id := h.Ident
+
+	p.open("ElfHeader {")
+	p.open("Ident {")
+	p.line("Magic: (% X)", id[:4])
+	switch class := elf.Class(id[elf.EI_CLASS]); class {
+	case elf.ELFCLASS32:
+		p.line("Class: 32-bit (0x%X)", uint8(class))
+	case elf.ELFCLASS64:
+		p.line("Class: 64-bit (0x%X)", uint8(class))
+	default:
+		p.line("Class: None (0x%X)", uint8(class))
+	}
+	switch data := elf.Data(id[elf.EI_DATA]); data {
+	case elf.ELFDATA2LSB:
+		p.line("DataEncoding: LittleEndian (0x%X)", uint8(data))
+	case elf.ELFDATA2MSB:
+		p.line("DataEncoding: BigEndian (0x%X)", uint8(data))
+	default:
+		p.line("DataEncoding: None (0x%X)", uint8(data))
+	}
+	p.line("FileVersion: %d", id[elf.EI_VERSION])
+	if name, ok := llvmOSABI[elf.OSABI(id[elf.EI_OSABI])]; ok {
+		p.line("OS/ABI: %s (0x%X)", name, id[elf.EI_OSABI])
+	} else {
+		p.line("OS/ABI: 0x%X", id[elf.EI_OSABI])
+	}
+	p.line("ABIVersion: %d", id[elf.EI_ABIVERSION])
+	p.line("Unused: (% X)", id[elf.EI_PAD:])
+	p.close("}")
+
+	if name, ok := llvmFileTypes[h.Type]; ok {
+		p.line("Type: %s (0x%X)", name, uint16(h.Type))
+	} else {
+		p.line("Type: 0x%X", uint16(h.Type))
+	}
+	if name := h.Machine.String(); strings.HasPrefix(name, "EM_") {
+		p.line("Machine: %s (0x%X)", name, uint16(h.Machine))
+	} else {
+		p.line("Machine: 0x%X", uint16(h.Machine))
+	}
+	p.line("Version: %d", uint32(h.Version))
+	p.line("Entry: 0x%X", h.Entry)
+	p.line("ProgramHeaderOffset: 0x%X", h.Phoff)
+	p.line("SectionHeaderOffset: 0x%X", h.Shoff)
+	switch h.Machine {
+	case elf.EM_MIPS:
+		p.flags("Flags", uint64(h.Flags), llvmMIPSFlags, 0xf000, 0xf0000000)
+	case elf.EM_RISCV:
+		p.flags("Flags", uint64(h.Flags), llvmRISCVFlags, 0x6)
+	default:
+		p.bits("Flags", uint64(h.Flags))
+	}
+	p.line("HeaderSize: %d", h.Ehsize)
+	p.line("ProgramHeaderEntrySize: %d", h.Phentsize)
+	phnum, shnum, shstrndx := headerCounts(h)
+	p.line("ProgramHeaderCount: %s", phnum)
+	p.line("SectionHeaderEntrySize: %d", h.Shentsize)
+	p.line("SectionHeaderCount: %s", shnum)
+	p.line("StringTableSectionIndex: %s", shstrndx)
+	p.close("}")
+}
+
+/* section headers */
+
+var llvmSectionTypes = map[elf.SectionType]string{
+	elf.SHT_NULL:           "SHT_NULL",
+	elf.SHT_PROGBITS:       "SHT_PROGBITS",
+	elf.SHT_SYMTAB:         "SHT_SYMTAB",
+	elf.SHT_STRTAB:         "SHT_STRTAB",
+	elf.SHT_RELA:           "SHT_RELA",
+	elf.SHT_HASH:           "SHT_HASH",
+	elf.SHT_DYNAMIC:        "SHT_DYNAMIC",
+	elf.SHT_NOTE:           "SHT_NOTE",
+	elf.SHT_NOBITS:         "SHT_NOBITS",
+	elf.SHT_REL:            "SHT_REL",
+	elf.SHT_SHLIB:          "SHT_SHLIB",
+	elf.SHT_DYNSYM:         "SHT_DYNSYM",
+	elf.SHT_INIT_ARRAY:     "SHT_INIT_ARRAY",
+	elf.SHT_FINI_ARRAY:     "SHT_FINI_ARRAY",
+	elf.SHT_PREINIT_ARRAY:  "SHT_PREINIT_ARRAY",
+	elf.SHT_GROUP:          "SHT_GROUP",
+	elf.SHT_SYMTAB_SHNDX:   "SHT_SYMTAB_SHNDX",
+	elf.SectionType(19):    "SHT_RELR",
+	0x60000001:             "SHT_ANDROID_REL",
+	0x60000002:             "SHT_ANDROID_RELA",
+	0x6fffff00:             "SHT_ANDROID_RELR",
+	0x6fff4c00:             "SHT_LLVM_ODRTAB",
+	0x6fff4c01:             "SHT_LLVM_LINKER_OPTIONS",
+	0x6fff4c03:             "SHT_LLVM_ADDRSIG",
+	0x6fff4c04:             "SHT_LLVM_DEPENDENT_LIBRARIES",
+	0x6fff4c05:             "SHT_LLVM_SYMPART",
+	0x6fff4c06:             "SHT_LLVM_PART_EHDR",
+	0x6fff4c07:             "SHT_LLVM_PART_PHDR",
+	0x6fff4c08:             "SHT_LLVM_BB_ADDR_MAP_V0",
+	0x6fff4c09:             "SHT_LLVM_CALL_GRAPH_PROFILE",
+	0x6fff4c0a:             "SHT_LLVM_BB_ADDR_MAP",
+	0x6fff4c0b:             "SHT_LLVM_OFFLOADING",
+	0x6fff4c0c:             "SHT_LLVM_LTO",
+	elf.SHT_GNU_ATTRIBUTES: "SHT_GNU_ATTRIBUTES",
+	elf.SHT_GNU_HASH:       "SHT_GNU_HASH",
+	elf.SHT_GNU_VERDEF:     "SHT_GNU_verdef",
+	elf.SHT_GNU_VERNEED:    "SHT_GNU_verneed",
+	elf.SHT_GNU_VERSYM:     "SHT_GNU_versym",
+}
+
+var llvmProcSectionTypes = map[elf.Machine]map[elf.SectionType]string{
+	elf.EM_X86_64: {0x70000001: "SHT_X86_64_UNWIND"},
+	elf.EM_RISCV:  {0x70000003: "SHT_RISCV_ATTRIBUTES"},
+	elf.EM_ARM: {
+		0x70000001: "SHT_ARM_EXIDX",
+		0x70000002: "SHT_ARM_PREEMPTMAP",
+		0x70000003: "SHT_ARM_ATTRIBUTES",
+		0x70000004: "SHT_ARM_DEBUGOVERLAY",
+		0x70000005: "SHT_ARM_OVERLAYSECTION",
+	},
+	elf.EM_MIPS: {
+		0x70000006: "SHT_MIPS_REGINFO",
+		0x7000000d: "SHT_MIPS_OPTIONS",
+		0x7000001e: "SHT_MIPS_DWARF",
+		0x7000002a: "SHT_MIPS_ABIFLAGS",
+	},
+	elf.EM_MSP430: {0x70000003: "SHT_MSP430_ATTRIBUTES"},
+}
+
+var llvmSectionFlags = []llvmFlag{
+	{"SHF_WRITE", uint64(elf.SHF_WRITE)},
+	{"SHF_ALLOC", uint64(elf.SHF_ALLOC)},
+	{"SHF_EXCLUDE", shfExclude},
+	{"SHF_EXECINSTR", uint64(elf.SHF_EXECINSTR)},
+	{"SHF_MERGE", uint64(elf.SHF_MERGE)},
+	{"SHF_STRINGS", uint64(elf.SHF_STRINGS)},
+	{"SHF_INFO_LINK", uint64(elf.SHF_INFO_LINK)},
+	{"SHF_LINK_ORDER", uint64(elf.SHF_LINK_ORDER)},
+	{"SHF_OS_NONCONFORMING", uint64(elf.SHF_OS_NONCONFORMING)},
+	{"SHF_GROUP", uint64(elf.SHF_GROUP)},
+	{"SHF_TLS", uint64(elf.SHF_TLS)},
+	{"SHF_COMPRESSED", uint64(elf.SHF_COMPRESSED)},
+	{"SHF_GNU_RETAIN", 0x200000},
+}
+
+var llvmProcSectionFlags = map[elf.Machine][]llvmFlag{
+	elf.EM_X86_64: {{"SHF_X86_64_LARGE", shfX8664Large}},
+	elf.EM_ARM:    {{"SHF_ARM_PURECODE", shfARMPurecode}},
+	elf.EM_MIPS: {
+		{"SHF_MIPS_NODUPES", 0x01000000},
+		{"SHF_MIPS_NAMES", 0x02000000},
+		{"SHF_MIPS_LOCAL", 0x04000000},
+		{"SHF_MIPS_NOSTRIP", 0x08000000},
+		{"SHF_MIPS_GPREL", 0x10000000},
+		{"SHF_MIPS_MERGE", 0x20000000},
+		{"SHF_MIPS_ADDR", 0x40000000},
+		{"SHF_MIPS_STRING", 0x80000000},
+	},
+}
+
+func llvmSectionType(elfFs *elfparse.ELFFile, t elf.SectionType) string {
+	if name, ok := llvmProcSectionTypes[elfFs.FileHdr.Machine][t]; ok {
+		return name
+	}
+	if name, ok := llvmSectionTypes[t]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+func llvmSections(p *llvmPrinter, elfFs *elfparse.ELFFile) {
+	flags := append(llvmSectionFlags[:len(llvmSectionFlags):len(llvmSectionFlags)], llvmProcSectionFlags[elfFs.FileHdr.Machine]...)
+
+	p.open("Sections [")
+	if !gnuNoSections(elfFs) {
+		for i, s := range elfFs.ElfSections.Section {
+			p.open("Section {")
+			p.line("Index: %d", i)
+			p.line("Name: %s (%d)", elfFs.ElfSections.SectionName[i], s.Name)
+			p.line("Type: %s (0x%X)", llvmSectionType(elfFs, s.Type), uint32(s.Type))
+			p.flags("Flags", uint64(s.Flags), flags)
+			p.line("Address: 0x%X", s.Addr)
+			p.line("Offset: 0x%X", s.Off)
+			p.line("Size: %d", s.Size)
+			p.line("Link: %d", s.Link)
+			p.line("Info: %d", s.Info)
+			p.line("AddressAlignment: %d", s.Addralign)
+			p.line("EntrySize: %d", s.Entsize)
+			p.close("}")
+		}
+	}
+	p.close("]")
+}
+
+/* program headers */
+
+var llvmSegmentTypes = map[elf.ProgType]string{
+	elf.PT_NULL:              "PT_NULL",
+	elf.PT_LOAD:              "PT_LOAD",
+	elf.PT_DYNAMIC:           "PT_DYNAMIC",
+	elf.PT_INTERP:            "PT_INTERP",
+	elf.PT_NOTE:              "PT_NOTE",
+	elf.PT_SHLIB:             "PT_SHLIB",
+	elf.PT_PHDR:              "PT_PHDR",
+	elf.PT_TLS:               "PT_TLS",
+	elf.PT_GNU_EH_FRAME:      "PT_GNU_EH_FRAME",
+	elf.ProgType(0x6464e550): "PT_SUNW_UNWIND",
+	elf.PT_GNU_STACK:         "PT_GNU_STACK",
+	elf.PT_GNU_RELRO:         "PT_GNU_RELRO",
+	elf.PT_GNU_PROPERTY:      "PT_GNU_PROPERTY",
+	elf.PT_OPENBSD_RANDOMIZE: "PT_OPENBSD_RANDOMIZE",
+	elf.PT_OPENBSD_WXNEEDED:  "PT_OPENBSD_WXNEEDED",
+	elf.PT_OPENBSD_BOOTDATA:  "PT_OPENBSD_BOOTDATA",
+}
+
+var llvmProcSegmentTypes = map[elf.Machine]map[elf.ProgType]string{
+	elf.EM_ARM: {0x70000001: "PT_ARM_EXIDX"},
+	elf.EM_MIPS: {
+		0x70000000: "PT_MIPS_REGINFO",
+		0x70000001: "PT_MIPS_RTPROC",
+		0x70000002: "PT_MIPS_OPTIONS",
+		0x70000003: "PT_MIPS_ABIFLAGS",
+	},
+}
+
+var llvmSegmentFlags = []llvmFlag{
+	{"PF_X", uint64(elf.PF_X)},
+	{"PF_W", uint64(elf.PF_W)},
+	{"PF_R", uint64(elf.PF_R)},
+}
+
+func llvmSegments(p *llvmPrinter, elfFs *elfparse.ELFFile) {
+	p.open("ProgramHeaders [")
+	for _, ph := range elfFs.ProgHeaders {
+		name, ok := llvmProcSegmentTypes[elfFs.FileHdr.Machine][ph.Type]
+		if !ok {
+			if name, ok = llvmSegmentTypes[ph.Type]; !ok {
+				name = "Unknown"
+			}
+		}
+
+		p.open("ProgramHeader {")
+		p.line("Type: %s (0x%X)", name, uint32(ph.Type))
+		p.line("Offset: 0x%X", ph.Off)
+		p.line("VirtualAddress: 0x%X", ph.Vaddr)
+		p.line("PhysicalAddress: 0x%X", ph.Paddr)
+		p.line("FileSize: %d", ph.Filesz)
+		p.line("MemSize: %d", ph.Memsz)
+		p.flags("Flags", uint64(ph.Flags), llvmSegmentFlags)
+		p.line("Alignment: %d", ph.Align)
+		p.close("}")
+	}
+	p.close("]")
+}
+
+/* dynamic section */
+
+func llvmDynTag(elfFs *elfparse.ELFFile, tag elf.DynTag) string {
+	name := elfFs.DynTagName(tag)
+	if !strings.HasPrefix(name, "DT_") || strings.Contains(name, "+") {
+		return fmt.Sprintf("<unknown:>0x%x", uint64(tag))
+	}
+	return strings.TrimPrefix(name, "DT_")
+}
+
+func llvmDynamic(p *llvmPrinter, elfFs *elfparse.ELFFile) {
+	if len(elfFs.Dynamic) == 0 {
+		return
+	}
+
+	width := 0
+	for _, entry := range elfFs.Dynamic {
+		width = max(width, len(llvmDynTag(elfFs, entry.Tag)))
+	}
+	tagWidth := 16
+	if is32(elfFs) {
+		tagWidth = 8
+	}
+
+	p.line("DynamicSection [ (%d entries)", len(elfFs.Dynamic))
+	p.line("  Tag%*sType%*sName/Value", tagWidth, "", max(width-3, 0), "")
+	for _, entry := range elfFs.Dynamic {
+		p.line("  0x%0*X %-*s %s", tagWidth, uint64(entry.Tag), width, llvmDynTag(elfFs, entry.Tag), llvmDynValue(elfFs, entry))
+	}
+	p.line("]")
+}
+
+func llvmDynValue(elfFs *elfparse.ELFFile, entry elfparse.DynEntry) string {
+	switch entry.Tag {
+	case elf.DT_NEEDED, elf.DT_SONAME, elf.DT_AUXILIARY, elf.DT_USED, elf.DT_FILTER, elf.DT_RPATH, elf.DT_RUNPATH:
+		str, err := elfFs.DynString(entry)
+		if err != nil {
+			str = "<?>"
+		}
+		switch entry.Tag {
+		case elf.DT_NEEDED:
+			return fmt.Sprintf("Shared library: [%s]", str)
+		case elf.DT_SONAME:
+			return fmt.Sprintf("Library soname: [%s]", str)
+		case elf.DT_AUXILIARY:
+			return fmt.Sprintf("Auxiliary library: [%s]", str)
+		case elf.DT_USED:
+			return fmt.Sprintf("Not needed object: [%s]", str)
+		case elf.DT_FILTER:
+			return fmt.Sprintf("Filter library: [%s]", str)
+		case elf.DT_RPATH:
+			return fmt.Sprintf("Library rpath: [%s]", str)
+		case elf.DT_RUNPATH:
+			return fmt.Sprintf("Library runpath: [%s]", str)
+		}
+
+	case elf.DT_PLTREL:
+		switch elf.DynTag(entry.Val) {
+		case elf.DT_REL:
+			return "REL"
+		case elf.DT_RELA:
+			return "RELA"
+		case elf.DynTag(36):
+			return "RELR"
+		}
+
+	case elf.DT_FLAGS, elf.DT_FLAGS_1:
+		/* llvm-readobj drops the bits it has no name for */
+		var b strings.Builder
+		for _, name := range elfparse.DynFlagNames(entry.Tag, entry.Val) {
+			if strings.HasPrefix(name, "DF_") {
+				b.WriteString(strings.TrimPrefix(strings.TrimPrefix(name, "DF_1_"), "DF_") + " ")
+			}
+		}
+		return b.String()
+
+	case elf.DT_VERDEFNUM, elf.DT_VERNEEDNUM, elf.DT_RELACOUNT, elf.DT_RELCOUNT:
+		return fmt.Sprintf("%d", entry.Val)
+	}
+
+	if elfFs.DynTagKind(entry.Tag) == elfparse.DynKindSize {
+		return fmt.Sprintf("%d (bytes)", entry.Val)
+	}
+	return fmt.Sprintf("0x%X", entry.Val)
+}
+
+/* relocations */
+
+func llvmRelocations(p *llvmPrinter, elfFs *elfparse.ELFFile) {
+	p.open("Relocations [")
+	for k := range elfFs.ElfSections.Section {
+		if relr, ok := elfFs.Relrs[uint32(k)]; ok && !gnuNoSections(elfFs) {
+			typ := "Unknown"
+			if t, ok := elfFs.RelativeType(); ok {
+				typ, _ = gnuRelocType(t, elfFs.FileHdr.Machine)
+			}
+			p.open("Section (%d) %s {", k, elfFs.ElfSections.SectionName[k])
+			for _, addr := range relr.Addrs {
+				p.line("0x%X %s -", addr, typ)
+			}
+			p.close("}")
+			continue
+		}
+
+		rels, ok := elfFs.Rels[uint32(k)]
+		if !ok || gnuNoSections(elfFs) {
+			continue
+		}
+		sec := elfFs.ElfSections.Section[k]
+		isDyn := sec.Link < uint32(len(elfFs.ElfSections.Section)) && elfFs.ElfSections.Section[sec.Link].Type == elf.SHT_DYNSYM
+
+		p.open("Section (%d) %s {", k, elfFs.ElfSections.SectionName[k])
+		for _, rel := range rels {
+			typ, ok := gnuRelocType(rel.Type, elfFs.FileHdr.Machine)
+			if !ok {
+				typ = "Unknown"
+			}
+
+			name := "-"
+			if rel.Sym != 0 {
+				if symbol, err := elfFs.RelocSymbol(uint32(k), rel); err == nil {
+					switch {
+					case symbol.NameOff == 0:
+						name = gnuSectionSymbolName(elfFs, symbol)
+					case isDyn:
+						name = elfFs.VersionedName(rel.Sym, displayName(symbol.Name, p.demangle))
+					default:
+						name = displayName(symbol.Name, p.demangle)
+					}
+				}
+			}
+
+			/* llvm-readobj unpacks both Android types into Elf_Rela */
+			if elfparse.HasAddends(sec.Type) || sec.Type == elfparse.SHT_ANDROID_REL {
+				p.line("0x%X %s %s 0x%X", rel.Off, typ, name, uint64(rel.Addend))
+			} else {
+				p.line("0x%X %s %s", rel.Off, typ, name)
+			}
+		}
+		p.close("}")
+	}
+	p.close("]")
+}
+
+/* symbol tables */
+
+var llvmSymBinds = map[elf.SymBind]string{
+	elf.STB_LOCAL:  "Local",
+	elf.STB_GLOBAL: "Global",
+	elf.STB_WEAK:   "Weak",
+	10:             "Unique",
+}
+
+var llvmSymTypes = map[elf.SymType]string{
+	elf.STT_NOTYPE:  "None",
+	elf.STT_OBJECT:  "Object",
+	elf.STT_FUNC:    "Function",
+	elf.STT_SECTION: "Section",
+	elf.STT_FILE:    "File",
+	elf.STT_COMMON:  "Common",
+	elf.STT_TLS:     "TLS",
+	10:              "GNU_IFunc",
+}
+
+/* not a mask, protected symbols list all three like llvm-readobj does */
+var llvmSymOther = []llvmFlag{
+	{"STV_INTERNAL", uint64(elf.STV_INTERNAL)},
+	{"STV_HIDDEN", uint64(elf.STV_HIDDEN)},
+	{"STV_PROTECTED", uint64(elf.STV_PROTECTED)},
+}
+
+func llvmSymSection(elfFs *elfparse.ELFFile, sym elfparse.Symbol) string {
+	shndx := sym.Shndx
+	switch ndx := elf.SectionIndex(shndx); {
+	case ndx == elf.SHN_UNDEF:
+		return "Undefined"
+	case ndx == elf.SHN_ABS:
+		return "Absolute"
+	case ndx == elf.SHN_COMMON:
+		return "Common"
+	case ndx == elf.SHN_XINDEX:
+		if sym.Section < uint32(len(elfFs.ElfSections.SectionName)) {
+			return elfFs.ElfSections.SectionName[sym.Section]
+		}
+		return "Extended"
+	case ndx >= elf.SHN_LOPROC && ndx <= elf.SHN_HIPROC:
+		return "Processor Specific"
+	case ndx >= elf.SHN_LOOS && ndx <= elf.SHN_HIOS:
+		return "Operating System Specific"
+	case ndx >= elf.SHN_LORESERVE:
+		return "Reserved"
+	case int(shndx) < len(elfFs.ElfSections.SectionName):
+		return elfFs.ElfSections.SectionName[shndx]
+	}
+	return "<?>"
+}
+
+func llvmSymbols(p *llvmPrinter, elfFs *elfparse.ELFFile, t elf.SectionType, q elfparse.SymbolQuery) {
+	label, symType := "Symbols [", elfparse.Sym
+	if t == elf.SHT_DYNSYM {
+		label, symType = "DynamicSymbols [", elfparse.DynSym
+	}
+
+	/* like llvm-readobj, tables found through DT_SYMTAB alone aren't listed */
+	var syms []elfparse.Symbol
+	if !gnuNoSections(elfFs) {
+		syms = elfFs.QuerySymbols(symType, q)
+	}
+
+	p.open(label)
+	for _, sym := range syms {
+		name := displayName(sym.Name, p.demangle)
+		switch {
+		case sym.NameOff == 0 && sym.Type() == elf.STT_SECTION:
+			name = gnuSectionSymbolName(elfFs, sym)
+		case t == elf.SHT_DYNSYM:
+			name = elfFs.VersionedName(sym.Index, name)
+		}
+
+		p.open("Symbol {")
+		p.line("Name: %s (%d)", name, sym.NameOff)
+		p.line("Value: 0x%X", sym.Value)
+		p.line("Size: %d", sym.Size)
+		if bind, ok := llvmSymBinds[sym.Bind()]; ok {
+			p.line("Binding: %s (0x%X)", bind, uint8(sym.Bind()))
+		} else {
+			p.line("Binding: 0x%X", uint8(sym.Bind()))
+		}
+		if typ, ok := llvmSymTypes[sym.Type()]; ok {
+			p.line("Type: %s (0x%X)", typ, uint8(sym.Type()))
+		} else {
+			p.line("Type: 0x%X", uint8(sym.Type()))
+		}
+		if sym.Other == 0 {
+			p.line("Other: 0")
+		} else {
+			p.flags("Other", uint64(sym.Other), llvmSymOther)
+		}
+		p.line("Section: %s (0x%X)", llvmSymSection(elfFs, sym), sym.Section)
+		p.close("}")
+	}
+	p.close("]")
+}
+
+/* hash tables */
+
+func llvmWords(vals []uint32, format string) string {
+	words := make([]string, len(vals))
+	for i, v := range vals {
+		words[i] = fmt.Sprintf(format, v)
+	}
+	return "[" + strings.Join(words, ", ") + "]"
+}
+
+func llvmHashTables(p *llvmPrinter, elfFs *elfparse.ELFFile) {
+	p.open("HashTable {")
+	if h := elfFs.Hash; h != nil {
+		p.line("Num Buckets: %d", len(h.Buckets))
+		p.line("Num Chains: %d", len(h.Chains))
+		p.line("Buckets: %s", llvmWords(h.Buckets, "%d"))
+		p.line("Chains: %s", llvmWords(h.Chains, "%d"))
+	}
+	p.close("}")
+
+	p.open("GnuHashTable {")
+	if h := elfFs.GNUHash; h != nil {
+		p.line("Num Buckets: %d", len(h.Buckets))
+		p.line("First Hashed Symbol Index: %d", h.SymOffset)
+		p.line("Num Mask Words: %d", len(h.Bloom))
+		p.line("Shift Count: %d", h.BloomShift)
+		bloom := make([]string, len(h.Bloom))
+		for i, w := range h.Bloom {
+			bloom[i] = fmt.Sprintf("0x%X", w)
+		}
+		p.line("Bloom Filter: [%s]", strings.Join(bloom, ", "))
+		p.line("Buckets: %s", llvmWords(h.Buckets, "%d"))
+		p.line("Values: %s", llvmWords(h.Chains, "0x%X"))
+	}
+	p.close("}")
+}
+
+/* version sections */
+
+var llvmVerFlags = []llvmFlag{
+	{"Base", elfparse.VER_FLG_BASE},
+	{"Weak", elfparse.VER_FLG_WEAK},
+	{"Info", elfparse.VER_FLG_INFO},
+}
+
+// llvmHasSection is whether llvm-readobj sees a section of type t; it
+// only reads section headers.
+func llvmHasSection(elfFs *elfparse.ELFFile, t elf.SectionType) bool {
+	ndx := elfFs.SectionsByType(t)
+	return len(ndx) > 0 && !elfFs.ElfSections.Section[ndx[0]].Synthetic
+}
+
+func llvmVersions(p *llvmPrinter, elfFs *elfparse.ELFFile) {
+	p.open("VersionSymbols [")
+	if llvmHasSection(elfFs, elf.SHT_GNU_VERSYM) && len(elfFs.Versym) == len(elfFs.DynSymbols) {
+		for i, sym := range elfFs.DynSymbols {
+			name := elfFs.VersionedName(sym.Index, displayName(sym.Name, p.demangle))
+			if sym.NameOff == 0 && sym.Type() == elf.STT_SECTION {
+				name = gnuSectionSymbolName(elfFs, sym)
+			}
+			p.open("Symbol {")
+			p.line("Version: %d", elfFs.Versym[i]&^elfparse.VERSYM_HIDDEN)
+			p.line("Name: %s", name)
+			p.close("}")
+		}
+	}
+	p.close("]")
+
+	p.open("VersionDefinitions [")
+	if llvmHasSection(elfFs, elf.SHT_GNU_VERDEF) {
+		for _, def := range elfFs.Verdef {
+			var name string
+			var parents []string
+			if len(def.Names) > 0 {
+				name, parents = def.Names[0], def.Names[1:]
+			}
+			p.open("Definition {")
+			p.line("Version: %d", def.Version)
+			p.flags("Flags", uint64(def.Flags), llvmVerFlags)
+			p.line("Index: %d", def.Ndx)
+			p.line("Hash: %d", def.Hash)
+			p.line("Name: %s", name)
+			p.line("Predecessors: [%s]", strings.Join(parents, ", "))
+			p.close("}")
+		}
+	}
+	p.close("]")
+
+	p.open("VersionRequirements [")
+	if llvmHasSection(elfFs, elf.SHT_GNU_VERNEED) {
+		for _, need := range elfFs.Verneed {
+			p.open("Dependency {")
+			p.line("Version: %d", need.Version)
+			p.line("Count: %d", len(need.Aux))
+			p.line("FileName: %s", need.File)
+			p.open("Entries [")
+			for _, aux := range need.Aux {
+				p.open("Entry {")
+				p.line("Hash: %d", aux.Hash)
+				p.flags("Flags", uint64(aux.Flags), llvmVerFlags)
+				p.line("Index: %d", aux.Other)
+				p.line("Name: %s", aux.Name)
+				p.close("}")
+			}
+			p.close("]")
+			p.close("}")
+		}
+	}
+	p.close("]")
+}
+
+/* notes */
+
+func llvmNotes(p *llvmPrinter, elfFs *elfparse.ELFFile) {
+	p.open("Notes [")
+	for _, table := range elfFs.Notes {
+		/* llvm-readobj only falls back to PT_NOTE without section headers */
+		name := table.Name
+		if name == "" && !gnuNoSections(elfFs) {
+			continue
+		} else if name == "" {
+			name = "<?>"
+		}
+		p.open("NoteSection {")
+		p.line("Name: %s", name)
+		p.line("Offset: 0x%X", table.Off)
+		p.line("Size: 0x%X", table.Size)
+		for _, note := range table.Notes {
+			p.open("Note {")
+			p.line("Owner: %s", note.Owner)
+			p.line("Data size: 0x%X", len(note.Desc))
+			p.line("Type: %s", llvmNoteType(elfFs, note))
+			if !llvmNoteDescription(p, elfFs, note) && len(note.Desc) > 0 {
+				p.binary("Description data", note.Desc)
+			}
+			p.close("}")
+		}
+		p.close("}")
+	}
+	p.close("]")
+}
+
+var llvmGNUNoteTypes = map[uint32]string{
+	elfparse.NT_GNU_ABI_TAG:         "NT_GNU_ABI_TAG (ABI version tag)",
+	elfparse.NT_GNU_HWCAP:           "NT_GNU_HWCAP (DSO-supplied software HWCAP info)",
+	elfparse.NT_GNU_BUILD_ID:        "NT_GNU_BUILD_ID (unique build ID bitstring)",
+	elfparse.NT_GNU_GOLD_VERSION:    "NT_GNU_GOLD_VERSION (gold version)",
+	elfparse.NT_GNU_PROPERTY_TYPE_0: "NT_GNU_PROPERTY_TYPE_0 (property note)",
+}
+
+var llvmFreeBSDNoteTypes = map[uint32]string{
+	elfparse.NT_FREEBSD_ABI_TAG:     "NT_FREEBSD_ABI_TAG (ABI version tag)",
+	elfparse.NT_FREEBSD_NOINIT_TAG:  "NT_FREEBSD_NOINIT_TAG (no .init tag)",
+	elfparse.NT_FREEBSD_ARCH_TAG:    "NT_FREEBSD_ARCH_TAG (architecture tag)",
+	elfparse.NT_FREEBSD_FEATURE_CTL: "NT_FREEBSD_FEATURE_CTL (FreeBSD feature control)",
+}
+
+var llvmGenericNoteTypes = map[uint32]string{
+	1:     "NT_VERSION (version)",
+	2:     "NT_ARCH (architecture)",
+	0x100: "OPEN",
+	0x101: "func",
+}
+
+// llvmNoteType names the note type by owner, core files aside: their
+// notes are named the same whoever wrote them.
+func llvmNoteType(elfFs *elfparse.ELFFile, n elfparse.Note) string {
+	names := llvmGenericNoteTypes
+	switch {
+	case n.Owner == "GNU":
+		names = llvmGNUNoteTypes
+	case n.Owner == "FreeBSD" && elfFs.Hdr.Type != elf.ET_CORE:
+		names = llvmFreeBSDNoteTypes
+	case elfFs.Hdr.Type == elf.ET_CORE:
+		if name := elfFs.NoteTypeName(elfparse.Note{Owner: "CORE", Type: n.Type}); !strings.HasPrefix(name, "Unknown") {
+			return name
+		}
+		names = nil
+	}
+	if name, ok := names[n.Type]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown (0x%08x)", n.Type)
+}
+
+var llvmFreeBSDFeatures = []llvmFlag{
+	{"ASLR_DISABLE", 0x1}, {"PROTMAX_DISABLE", 0x2}, {"STKGAP_DISABLE", 0x4},
+	{"WXNEEDED", 0x8}, {"LA48", 0x10}, {"ASG_DISABLE", 0x20},
+}
+
+// llvmNoteDescription prints the fields of the notes llvm-readobj
+// decodes. It returns false for the others, whose bytes are dumped.
+func llvmNoteDescription(p *llvmPrinter, elfFs *elfparse.ELFFile, n elfparse.Note) bool {
+	bo := elfFs.FileHdr.Endianness
+	switch {
+	case n.Owner == "GNU" && n.Type == elfparse.NT_GNU_ABI_TAG:
+		if len(n.Desc) < 16 {
+			p.line("ABI: <corrupt GNU_ABI_TAG>")
+			return false
+		}
+		os := "Unknown"
+		if v := bo.Uint32(n.Desc); v < uint32(len(gnuABITagOS)) {
+			os = gnuABITagOS[v]
+		}
+		p.line("OS: %s", os)
+		p.line("ABI: %d.%d.%d", bo.Uint32(n.Desc[4:]), bo.Uint32(n.Desc[8:]), bo.Uint32(n.Desc[12:]))
+
+	case n.Owner == "GNU" && n.Type == elfparse.NT_GNU_BUILD_ID:
+		p.line("Build ID: %x", n.Desc)
+
+	case n.Owner == "GNU" && n.Type == elfparse.NT_GNU_GOLD_VERSION:
+		p.line("Version: %s", n.Desc)
+
+	case n.Owner == "GNU" && n.Type == elfparse.NT_GNU_PROPERTY_TYPE_0:
+		p.open("Property [")
+		for _, prop := range llvmGNUProperties(elfFs, n.Desc) {
+			p.line("%s", prop)
+		}
+		p.close("]")
+
+	case n.Owner != "FreeBSD" || elfFs.Hdr.Type == elf.ET_CORE:
+		return false
+
+	case n.Type == elfparse.NT_FREEBSD_ABI_TAG && len(n.Desc) == 4:
+		p.line("ABI tag: %d", bo.Uint32(n.Desc))
+
+	case n.Type == elfparse.NT_FREEBSD_ARCH_TAG:
+		p.line("Arch tag: %s", n.Desc)
+
+	case n.Type == elfparse.NT_FREEBSD_FEATURE_CTL && len(n.Desc) == 4:
+		v := bo.Uint32(n.Desc)
+		var names string
+		for _, f := range llvmFreeBSDFeatures {
+			if uint64(v)&f.value == f.value {
+				names += f.name + " "
+			}
+		}
+		if names == "" {
+			p.line("Feature flags: 0x%X", v)
+		} else {
+			p.line("Feature flags: %s(0x%X)", names, v)
+		}
+
+	default:
+		return false
+	}
+	return true
+}
+
+var llvmX86Feature1 = []llvmFlag{{"IBT", 0x1}, {"SHSTK", 0x2}}
+
+var llvmAArch64Feature1 = []llvmFlag{{"BTI", 0x1}, {"PAC", 0x2}}
+
+var llvmX86Feature2 = []llvmFlag{
+	{"x86", 0x1}, {"x87", 0x2}, {"MMX", 0x4}, {"XMM", 0x8}, {"YMM", 0x10},
+	{"ZMM", 0x20}, {"FXSR", 0x40}, {"XSAVE", 0x80}, {"XSAVEOPT", 0x100}, {"XSAVEC", 0x200},
+}
+
+var llvmX86ISA1 = []llvmFlag{{"x86-64-baseline", 0x1}, {"x86-64-v2", 0x2}, {"x86-64-v3", 0x4}, {"x86-64-v4", 0x8}}
+
+// llvmGNUProperties decodes a NT_GNU_PROPERTY_TYPE_0 note as llvm-readobj
+// does, which unlike readelf decodes x86 properties on any machine.
+func llvmGNUProperties(elfFs *elfparse.ELFFile, desc []byte) []string {
+	bo := elfFs.FileHdr.Endianness
+	word := uint64(8)
+	if is32(elfFs) {
+		word = 4
+	}
+
+	var props []string
+	for len(desc) >= 8 {
+		typ, size := bo.Uint32(desc), bo.Uint32(desc[4:])
+		desc = desc[8:]
+		padded := (uint64(size) + word - 1) &^ (word - 1)
+		if uint64(len(desc)) < padded {
+			props = append(props, fmt.Sprintf("<corrupt type (0x%x) datasz: 0x%x>", typ, size))
+			break
+		}
+		data := desc[:size]
+		desc = desc[padded:]
+
+		var prefix string
+		var bits []llvmFlag
+		switch typ {
+		case elfparse.GNU_PROPERTY_STACK_SIZE:
+			switch {
+			case uint64(size) != word:
+				props = append(props, fmt.Sprintf("stack size: <corrupt length: 0x%x>", size))
+			case word == 4:
+				props = append(props, fmt.Sprintf("stack size: 0x%x", bo.Uint32(data)))
+			default:
+				props = append(props, fmt.Sprintf("stack size: 0x%x", bo.Uint64(data)))
+			}
+			continue
+		case elfparse.GNU_PROPERTY_NO_COPY_ON_PROTECTED:
+			if size != 0 {
+				props = append(props, fmt.Sprintf("no copy on protected <corrupt length: 0x%x>", size))
+			} else {
+				props = append(props, "no copy on protected")
+			}
+			continue
+		case elfparse.GNU_PROPERTY_AARCH64_FEATURE_1_AND:
+			prefix, bits = "aarch64 feature: ", llvmAArch64Feature1
+		case elfparse.GNU_PROPERTY_X86_FEATURE_1_AND:
+			prefix, bits = "x86 feature: ", llvmX86Feature1
+		case elfparse.GNU_PROPERTY_X86_FEATURE_2_NEEDED:
+			prefix, bits = "x86 feature needed: ", llvmX86Feature2
+		case elfparse.GNU_PROPERTY_X86_FEATURE_2_USED:
+			prefix, bits = "x86 feature used: ", llvmX86Feature2
+		case elfparse.GNU_PROPERTY_X86_ISA_1_NEEDED:
+			prefix, bits = "x86 ISA needed: ", llvmX86ISA1
+		case elfparse.GNU_PROPERTY_X86_ISA_1_USED:
+			prefix, bits = "x86 ISA used: ", llvmX86ISA1
+		default:
+			props = append(props, fmt.Sprintf("<application-specific type 0x%x>", typ))
+			continue
+		}
+
+		if size != 4 {
+			props = append(props, fmt.Sprintf("%s<corrupt length: 0x%x>", prefix, size))
+			continue
+		}
+		v := uint64(bo.Uint32(data))
+		if v == 0 {
+			props = append(props, prefix+"<None>")
+			continue
+		}
+		var names []string
+		for _, f := range bits {
+			if v&f.value != 0 {
+				names = append(names, f.name)
+				v &^= f.value
+			}
+		}
+		if v != 0 {
+			names = append(names, fmt.Sprintf("<unknown flags: 0x%x>", v))
+		}
+		props = append(props, prefix+strings.Join(names, ", "))
+	}
+	if len(desc) > 0 {
+		props = append(props, "<corrupted GNU_PROPERTY_TYPE_0>")
+	}
+	return props
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+// testdata/*.llvm.golden are "llvm-readobj -h -S -l -d -r -s --dyn-symbols
+// -V -n --hash-table --gnu-hash-table" (LLVM 14) of the files of the same
+// name in elfparse/testdata.
+func TestLLVMMatchesReadobj(t *testing.T) {
+	for _, name := range goldenFiles {
+		t.Run(name, func(t *testing.T) {
+			c, err := parseArgs([]string{"--compat=llvm", "-hSlsrdVnI", "../../elfparse/testdata/" + name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			elfFs, err := elfparse.OpenOptions(c.files[0], c.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer elfFs.Close()
+
+			var buf bytes.Buffer
+			printLLVM(&buf, elfFs, c.files[0], c.m)
+			compareGolden(t, buf.String(), "testdata/"+name+".llvm.golden")
+		})
+	}
+}
@@ -0,0 +1,700 @@
+package main
+
+import (
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+func printProgHeaders(elfFs *elfparse.ELFFile) {
+	fmt.Printf("%d program header entries\n", len(elfFs.ProgHeaders))
+	fmt.Printf("  \t\t\t\t\t\t\tAddress\t\t\t\tSize\n")
+	fmt.Printf("  Num:\tType\t\t\tFlags\t\tOffset\tVirtual\t\tPhysical\tFile\tMemory\tAlign\n")
+	for i, entry := range elfFs.ProgHeaders {
+		flag := entry.Flags.String()
+		fmt.Printf("  %d\t%-20s\t%-16s0x%-4s\t0x%-8s\t0x%-8s\t%-4d\t%-4d\t%-4d\n", i, elfFs.ProgTypeName(entry.Type), flag, fmt.Sprintf("%X", entry.Off), fmt.Sprintf("%X", entry.Vaddr), fmt.Sprintf("%X", entry.Paddr), entry.Filesz, entry.Memsz, entry.Align)
+		if entry.Type == elf.PT_INTERP {
+			if interp, ok := elfFs.Interp(); ok {
+				fmt.Printf("      [Requesting program interpreter: %s]\n", interp)
+			}
+		}
+	}
+
+	if len(elfFs.ElfSections.Section) == 0 {
+		return
+	}
+	fmt.Printf("\n Section to Segment mapping:\n")
+	fmt.Printf("  Segment Sections...\n")
+	for i := range elfFs.ProgHeaders {
+		fmt.Printf("   %02d     ", i)
+		for _, sNdx := range elfFs.SegmentSections(i) {
+			fmt.Printf("%s ", elfFs.ElfSections.SectionName[sNdx])
+		}
+		fmt.Println()
+	}
+}
+
+func printDynamic(elfFs *elfparse.ELFFile, demangle bool) {
+	if len(elfFs.Dynamic) == 0 {
+		fmt.Println("There is no dynamic section in this file.")
+		return
+	}
+
+	fmt.Printf("Dynamic section contains %d entries:\n", len(elfFs.Dynamic))
+	fmt.Println("  Tag\t\t\tType\t\t\t\tName/Value")
+	for _, entry := range elfFs.Dynamic {
+		fmt.Printf("  0x%016x\t%-24s\t%s\n", uint64(entry.Tag), elfFs.DynTagName(entry.Tag), dynValueString(elfFs, entry, demangle))
+	}
+}
+
+func dynValueString(elfFs *elfparse.ELFFile, entry elfparse.DynEntry, demangle bool) string {
+	switch elfFs.DynTagKind(entry.Tag) {
+	case elfparse.DynKindString:
+		str, err := elfFs.DynString(entry)
+		if err != nil {
+			return fmt.Sprintf("<corrupt: 0x%x>", entry.Val)
+		}
+		str = displayName(str, demangle)
+		switch entry.Tag {
+		case elf.DT_NEEDED:
+			return fmt.Sprintf("Shared library: [%s]", str)
+		case elf.DT_SONAME:
+			return fmt.Sprintf("Library soname: [%s]", str)
+		case elf.DT_RPATH:
+			return fmt.Sprintf("Library rpath: [%s]", str)
+		case elf.DT_RUNPATH:
+			return fmt.Sprintf("Library runpath: [%s]", str)
+		}
+		return str
+	case elfparse.DynKindFlags:
+		return strings.Join(elfparse.DynFlagNames(entry.Tag, entry.Val), " ")
+	case elfparse.DynKindTag:
+		return elfFs.DynTagName(elf.DynTag(entry.Val))
+	case elfparse.DynKindSize:
+		return fmt.Sprintf("%d (bytes)", entry.Val)
+	case elfparse.DynKindAddress:
+		return fmt.Sprintf("0x%x", entry.Val)
+	}
+	return fmt.Sprintf("%d", entry.Val)
+}
+
+func printNotes(elfFs *elfparse.ELFFile) {
+	if len(elfFs.Notes) == 0 {
+		fmt.Println("There are no notes in this file.")
+		return
+	}
+
+	for _, table := range elfFs.Notes {
+		if table.Name != "" {
+			fmt.Printf("\nDisplaying notes found in: %s\n", table.Name)
+		} else {
+			fmt.Printf("\nDisplaying notes found at file offset 0x%08x with length 0x%08x:\n", table.Off, table.Size)
+		}
+		fmt.Println("  Owner                Data size \tDescription")
+		for _, note := range table.Notes {
+			fmt.Printf("  %-20s 0x%08x\t%s\n", note.Owner, len(note.Desc), elfFs.NoteTypeName(note))
+			for _, line := range elfFs.NoteDescription(note) {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+}
+
+func printVersions(elfFs *elfparse.ELFFile, demangle bool) {
+	if len(elfFs.Verdef) == 0 && len(elfFs.Verneed) == 0 {
+		fmt.Println("No version information found in this file.")
+		return
+	}
+
+	if len(elfFs.Verdef) > 0 {
+		fmt.Printf("\nVersion definitions contain %d entries:\n", len(elfFs.Verdef))
+		for _, def := range elfFs.Verdef {
+			name := ""
+			if len(def.Names) > 0 {
+				name = displayName(def.Names[0], demangle)
+			}
+			fmt.Printf("  0x%04x: Rev: %d  Flags: %s  Index: %d  Name: %s\n", def.Off, def.Version, verFlags(def.Flags), def.Ndx, name)
+			for i := 1; i < len(def.Names); i++ {
+				fmt.Printf("\tParent %d: %s\n", i, displayName(def.Names[i], demangle))
+			}
+		}
+	}
+
+	if len(elfFs.Verneed) > 0 {
+		fmt.Printf("\nVersion needs contain %d entries:\n", len(elfFs.Verneed))
+		for _, need := range elfFs.Verneed {
+			fmt.Printf("  0x%04x: Version: %d  File: %s  Cnt: %d\n", need.Off, need.Version, need.File, len(need.Aux))
+			for _, aux := range need.Aux {
+				fmt.Printf("  0x%04x:   Name: %s  Flags: %s  Version: %d\n", aux.Off, displayName(aux.Name, demangle), verFlags(aux.Flags), aux.Other)
+			}
+		}
+	}
+}
+
+func verFlags(flags uint16) string {
+	if flags == 0 {
+		return "none"
+	}
+	var names []string
+	if flags&elfparse.VER_FLG_BASE != 0 {
+		names = append(names, "BASE")
+	}
+	if flags&elfparse.VER_FLG_WEAK != 0 {
+		names = append(names, "WEAK")
+	}
+	if flags&elfparse.VER_FLG_INFO != 0 {
+		names = append(names, "INFO")
+	}
+	if rest := flags &^ (elfparse.VER_FLG_BASE | elfparse.VER_FLG_WEAK | elfparse.VER_FLG_INFO); rest != 0 {
+		names = append(names, fmt.Sprintf("<unknown: %x>", rest))
+	}
+	return strings.Join(names, " | ")
+}
+
+func resolveRelocType(rType uint32, mType elf.Machine) string {
+	switch mType {
+	case elf.EM_X86_64:
+		return fmt.Sprintf("%s", elf.R_X86_64(rType))
+	case elf.EM_386:
+		return fmt.Sprintf("%s", elf.R_386(rType))
+	case elf.EM_ARM:
+		return fmt.Sprintf("%s", elf.R_ARM(rType))
+	case elf.EM_AARCH64:
+		return fmt.Sprintf("%s", elf.R_AARCH64(rType))
+	case elf.EM_PPC:
+		return fmt.Sprintf("%s", elf.R_PPC(rType))
+	case elf.EM_PPC64:
+		return fmt.Sprintf("%s", elf.R_PPC64(rType))
+	case elf.EM_MIPS:
+		return fmt.Sprintf("%s", elf.R_MIPS(rType))
+	case elf.EM_RISCV:
+		return fmt.Sprintf("%s", elf.R_RISCV(rType))
+	case elf.EM_S390:
+		return fmt.Sprintf("%s", elf.R_390(rType))
+	case elf.EM_SPARCV9:
+		return fmt.Sprintf("%s", elf.R_SPARC(rType))
+	default:
+		return "R_UNKNOWN"
+	}
+}
+
+func printRelocations(elfFs *elfparse.ELFFile, demangle bool) {
+	for k := range elfFs.ElfSections.Section {
+		if _, ok := elfFs.Rels[uint32(k)]; ok {
+			printRelocSection(elfFs, uint32(k), demangle)
+		}
+		if relr, ok := elfFs.Relrs[uint32(k)]; ok {
+			printRelrSection(elfFs, uint32(k), relr)
+		}
+	}
+}
+
+// relocCount is how many relocations of one type a section has.
+type relocCount struct {
+	name string
+	n    int
+}
+
+// relocCounts tallies the relocations of section k by type, most frequent
+// first. RELR addresses count as the machine's RELATIVE relocation.
+func relocCounts(elfFs *elfparse.ELFFile, k uint32) []relocCount {
+	byType := map[uint32]int{}
+	for _, rel := range elfFs.Rels[k] {
+		byType[rel.Type]++
+	}
+	if relr, ok := elfFs.Relrs[k]; ok && len(relr.Addrs) > 0 {
+		t, _ := elfFs.RelativeType()
+		byType[t] += len(relr.Addrs)
+	}
+
+	var counts []relocCount
+	for t, n := range byType {
+		counts = append(counts, relocCount{resolveRelocType(t, elfFs.FileHdr.Machine), n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].n != counts[j].n {
+			return counts[i].n > counts[j].n
+		}
+		return counts[i].name < counts[j].name
+	})
+	return counts
+}
+
+// printRelocSummary is -r under --reloc-summary, counts instead of the
+// tables. No other tool has it, so every text layout prints it this way.
+func printRelocSummary(elfFs *elfparse.ELFFile) {
+	total := 0
+	for k := range elfFs.ElfSections.Section {
+		rels, ok := elfFs.Rels[uint32(k)]
+		relr, isRelr := elfFs.Relrs[uint32(k)]
+		if !ok && !isRelr {
+			continue
+		}
+
+		name := elfFs.ElfSections.SectionName[k]
+		if isRelr {
+			fmt.Printf("\nSection %s has %d relative relocations in %d RELR entries\n", name, len(relr.Addrs), relr.Words)
+			total += len(relr.Addrs)
+		} else {
+			fmt.Printf("\nSection %s has %d relocation entries\n", name, len(rels))
+			total += len(rels)
+		}
+		for _, c := range relocCounts(elfFs, uint32(k)) {
+			fmt.Printf("  %-30s %d\n", c.name, c.n)
+		}
+	}
+	fmt.Printf("\n%d relocations in total\n", total)
+}
+
+func printRelrSection(elfFs *elfparse.ELFFile, k uint32, relr elfparse.RelrTable) {
+	fmt.Printf("\nSection %s has %d relative relocations in %d RELR entries\n\n", elfFs.ElfSections.SectionName[k], len(relr.Addrs), relr.Words)
+	fmt.Println("Offset\t\t\tType")
+	relName := "R_UNKNOWN"
+	if t, ok := elfFs.RelativeType(); ok {
+		relName = resolveRelocType(t, elfFs.FileHdr.Machine)
+	}
+	for _, addr := range relr.Addrs {
+		fmt.Printf("%016x\t%s\n", addr, relName)
+	}
+}
+
+func printRelocSection(elfFs *elfparse.ELFFile, k uint32, demangle bool) {
+	r := elfFs.Rels[k]
+	sName := elfFs.ElfSections.SectionName[k]
+	isRela := elfparse.HasAddends(elfFs.ElfSections.Section[k].Type)
+	link := elfFs.ElfSections.Section[k].Link
+	isDyn := link < uint32(len(elfFs.ElfSections.Section)) && elfFs.ElfSections.Section[link].Type == elf.SHT_DYNSYM
+
+	l := len(r)
+	fmt.Printf("\nSection %s has %d relocation entries\n\n", sName, l)
+	if isRela {
+		fmt.Println("Offset\t\t\tInfo\t\t\t\tType\t\t\tSym.Value\t\t\tSym.Name + Addend")
+	} else {
+		fmt.Println("Offset\t\t\tInfo\t\t\t\tType\t\t\tSym.Value\t\t\tSym.Name")
+	}
+
+	for rNdx := 0; rNdx < l; rNdx++ {
+		o := r[rNdx].Off
+		t := r[rNdx].Type
+		s := r[rNdx].Sym
+		i := r[rNdx].Info
+
+		relName := resolveRelocType(t, elfFs.FileHdr.Machine)
+
+		symbol, err := elfFs.RelocSymbol(k, r[rNdx])
+		symName := displayName(symbol.Name, demangle)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			symName = "<unknown>"
+		} else if isDyn {
+			symName = elfFs.VersionedName(s, symName)
+		}
+
+		if !isRela {
+			fmt.Printf("%016x\t%016x\t%s\t%016x\t\t%s\n", o, i, relName, symbol.Value, symName)
+			continue
+		}
+		if s != uint32(elf.SHN_UNDEF) {
+			symName += " + "
+		}
+		fmt.Printf("%016x\t%016x\t%s\t%016x\t\t%s%d\n", o, i, relName, symbol.Value, symName, r[rNdx].Addend)
+	}
+}
+
+// displayName is a symbol name as printed, decoded under -C.
+func displayName(name string, demangle bool) string {
+	if demangle {
+		return elfparse.Demangle(name)
+	}
+	return name
+}
+
+func printSymbols(elfFs *elfparse.ELFFile, symType int, syms []elfparse.Symbol, demangle bool) {
+	fmt.Printf("  Num:\tValue\t\tSize \tType\t\tBind\t\tVis\t\tNdx\t\tName\n")
+	for _, sym := range syms {
+		nm := displayName(sym.Name, demangle)
+		if symType == elfparse.DynSym {
+			nm = elfFs.VersionedName(sym.Index, nm)
+		}
+		fmt.Printf("  %-5d %08x\t%d\t%s\t%s\t%s\t%d\t%s\n", sym.Index, sym.Value, sym.Size, sym.Type(), sym.Bind(), sym.Visibility(), sym.Section, nm)
+	}
+}
+
+func printSymbolTables(elfFs *elfparse.ELFFile, q elfparse.SymbolQuery, demangle bool) {
+	if len(elfFs.DynSymbols) > 0 {
+		syms := elfFs.QuerySymbols(elfparse.DynSym, q)
+		fmt.Printf("%d entries found in .dynsym%s\n", len(elfFs.DynSymbols), shownCount(q, syms))
+		printSymbols(elfFs, elfparse.DynSym, syms, demangle)
+	} else {
+		fmt.Println("No Dynamic symbols found - .dynsym missing from target")
+	}
+
+	if len(elfFs.Symbols) > 0 {
+		syms := elfFs.QuerySymbols(elfparse.Sym, q)
+		fmt.Printf("%d entries found in .symtab%s\n", len(elfFs.Symbols), shownCount(q, syms))
+		printSymbols(elfFs, elfparse.Sym, syms, demangle)
+	} else {
+		fmt.Println("Section .symtab mising -- Binary is stripped no exported symbols available !")
+	}
+}
+
+func shownCount(q elfparse.SymbolQuery, syms []elfparse.Symbol) string {
+	if !q.Active() {
+		return ""
+	}
+	return fmt.Sprintf(", %d shown", len(syms))
+}
+
+func printSections(elfFs *elfparse.ELFFile, m modes) {
+	ElfSections := elfFs.ElfSections
+	fmt.Printf("%d Sections @ Offset 0x%x\n", len(ElfSections.Section), elfFs.Hdr.Shoff)
+
+	/* addresses are printed at the natural width of the class */
+	w, pad := 16, ""
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		w, pad = 8, "\t"
+	}
+
+	if len(ElfSections.Section) > 0 && ElfSections.Section[0].Synthetic {
+		fmt.Println("Section headers missing or bogus, sections marked * were reconstructed from PT_DYNAMIC")
+	}
+
+	switch {
+	case m.sectionDetails:
+		fmt.Println("[NR]  Name")
+		fmt.Println("      Type\t\tAddress\t\t\tOffsets\t\tSize\t\t\tEntSize\t\tLink Info\tAlign")
+		fmt.Println("      Flags")
+	case m.wide:
+		fmt.Println("[NR]  Name\t\t\tType\t\tAddress\t\t\tOffsets\t\tSize\t\t\tEntSize\t\tFlags Link Info\t\tAlign")
+	default:
+		fmt.Println("[NR]  Name\t\t\tType\t\tAddress\t\t\tOffsets")
+		fmt.Println("      Size\t\t\tEntSize\t\tFlags Link Info\t\tAlign")
+	}
+	for i, section := range ElfSections.Section {
+		a := section.Addr
+		o := section.Off
+		s := section.Size
+		e := section.Entsize
+		l := section.Link
+		f := flagToKey(fmt.Sprintf("%s", section.Flags))
+		info := section.Info
+		align := section.Addralign
+		nm := ElfSections.SectionName[i]
+
+		/* SHT_REL string throws off alignment, this is a hack to maintain alignment for display purposes */
+		t := fmt.Sprintf("%s", section.Type)
+		if t == "SHT_REL" {
+			t += " "
+		}
+		switch section.Type {
+		case elfparse.SHT_RELR:
+			t = "SHT_RELR"
+		case elfparse.SHT_ANDROID_REL:
+			t = "SHT_ANDROID_REL"
+		case elfparse.SHT_ANDROID_RELA:
+			t = "SHT_ANDROID_RELA"
+		case elfparse.SHT_ANDROID_RELR:
+			t = "SHT_ANDROID_RELR"
+		}
+
+		mark := " "
+		if section.Synthetic {
+			mark = "*"
+		}
+
+		switch {
+		case m.sectionDetails:
+			fmt.Printf("[%-2d]%s %s\n", i, mark, nm)
+			fmt.Printf("      %s\t%0*x\t%s%08x\t%0*x\t\t%s%0*x%s  %-5d%d\t\t%5d\n", t, w, a, pad, o, w, s, pad, w, e, pad, l, info, align)
+			fmt.Printf("      [0x%x]: %s\n", uint64(section.Flags), sectionFlagNames(section.Flags))
+		case m.wide:
+			fmt.Printf("[%-2d]%s %-20s\t%s\t%0*x\t%s%08x\t%0*x\t\t%s%0*x%s  %-5s%-5d%d\t\t%5d\n", i, mark, nm, t, w, a, pad, o, w, s, pad, w, e, pad, f, l, info, align)
+		default:
+			fmt.Printf("[%-2d]%s %-20s\t%s\t%0*x\t%s%08x\n", i, mark, nm, t, w, a, pad, o)
+			fmt.Printf("      %0*x\t\t%s%0*x%s  %-5s%-5d%d\t\t%5d\n", w, s, pad, w, e, pad, f, l, info, align)
+		}
+		if chdr, ok := elfFs.Compression(uint32(i)); ok {
+			fmt.Printf("      %s: 0x%x bytes, 0x%x uncompressed, align %d\n", compressionName(chdr.Type), s, chdr.Size, chdr.Addralign)
+		}
+	}
+
+	if m.sectionDetails {
+		return
+	}
+	fmt.Println("Key to Flags:")
+	fmt.Println("W (write), A (alloc), X (executable), M (merge), S (strings), I (info)")
+	fmt.Println("L (link order), O (extra os processing required), G (group), T (TLS)")
+	fmt.Println("C (compressed), p (processor specific)")
+}
+
+// sectionFlagNames spells the flags out for -t, "SHF_WRITE, SHF_ALLOC".
+func sectionFlagNames(flags elf.SectionFlag) string {
+	if flags == 0 {
+		return "none"
+	}
+	return strings.ReplaceAll(flags.String(), "+", ", ")
+}
+
+func compressionName(t elf.CompressionType) string {
+	switch t {
+	case elf.COMPRESS_ZLIB:
+		return "ZLIB"
+	case elf.COMPRESS_ZSTD:
+		return "ZSTD"
+	}
+	return fmt.Sprintf("compression type %d", uint32(t))
+}
+
+func flagToKey(flag string) (key string) {
+	if strings.Contains(flag, "SHF_WRITE") {
+		key += "W"
+	}
+
+	if strings.Contains(flag, "SHF_ALLOC") {
+		key += "A"
+	}
+
+	if strings.Contains(flag, "SHF_EXECINSTR") {
+		key += "X"
+	}
+
+	if strings.Contains(flag, "SHF_MERGE") {
+		key += "M"
+	}
+
+	if strings.Contains(flag, "SHF_STRINGS") {
+		key += "S"
+	}
+
+	if strings.Contains(flag, "SHF_INFO_LINK") {
+		key += "I"
+	}
+
+	if strings.Contains(flag, "SHF_LINK_ORDER") {
+		key += "L"
+	}
+
+	if strings.Contains(flag, "SHF_OS_NONCONFORMING") {
+		key += "O"
+	}
+
+	if strings.Contains(flag, "SHF_GROUP") {
+		key += "G"
+	}
+
+	if strings.Contains(flag, "SHF_TLS") {
+		key += "T"
+	}
+
+	if strings.Contains(flag, "SHF_COMPRESSED") {
+		key += "C"
+	}
+
+	if strings.Contains(flag, "SHF_MASKOS") {
+		key += "o"
+	}
+
+	if strings.Contains(flag, "SHF_MASKPROC") {
+		key += "P"
+	}
+	return
+}
+
+func printHeader(h elfparse.Header) {
+	fmt.Printf("-------------------------- Elf Header ------------------------\n")
+	fmt.Printf("Magic: % x\n", h.Ident)
+	fmt.Printf("Class: %s\n", h.Class)
+	fmt.Printf("Data: %s\n", elf.Data(h.Ident[elf.EI_DATA]))
+	fmt.Printf("Version: %s\n", h.Version)
+	fmt.Printf("OS/ABI: %s\n", elf.OSABI(h.Ident[elf.EI_OSABI]))
+	fmt.Printf("ABI Version: %d\n", h.Ident[elf.EI_ABIVERSION])
+	fmt.Printf("Elf Type: %s\n", h.Type)
+	fmt.Printf("Machine: %s\n", h.Machine)
+	fmt.Printf("Entry: 0x%x\n", h.Entry)
+	fmt.Printf("Program Header Offset: 0x%x\n", h.Phoff)
+	fmt.Printf("Section Header Offset: 0x%x\n", h.Shoff)
+	fmt.Printf("Flags: 0x%x\n", h.Flags)
+	fmt.Printf("Elf Header Size (bytes): %d\n", h.Ehsize)
+	fmt.Printf("Program Header Entry Size (bytes): %d\n", h.Phentsize)
+	phnum, shnum, shstrndx := headerCounts(h)
+	fmt.Printf("Number of Program Header Entries: %s\n", phnum)
+	fmt.Printf("Size of Section Header Entry: %d\n", h.Shentsize)
+	fmt.Printf("Number of Section Header Entries: %s\n", shnum)
+	fmt.Printf("Index of section header string table: %s\n", shstrndx)
+}
+
+// headerCounts formats e_phnum, e_shnum and e_shstrndx, followed by the
+// real value from section header 0 where the field is an extended
+// numbering escape, like "0 (70009)".
+func headerCounts(h elfparse.Header) (phnum, shnum, shstrndx string) {
+	count := func(raw uint16, escaped bool, real uint32) string {
+		if escaped && h.Shoff != 0 {
+			return fmt.Sprintf("%d (%d)", raw, real)
+		}
+		return fmt.Sprintf("%d", raw)
+	}
+	return count(h.Phnum, h.Phnum == elfparse.PN_XNUM, h.SegmentCount),
+		count(h.Shnum, h.Shnum == 0, h.SectionCount),
+		count(h.Shstrndx, h.Shstrndx == uint16(elf.SHN_XINDEX), h.ShstrIndex)
+}
+
+// modes are the tables selected on the command line.
+type modes struct {
+	header, sections, symbols, relocations, progHeaders, dynamic, notes, versions bool
+	histogram, relocSummary, sectionDetails, wide, demangle                       bool
+	dumps                                                                         []dump
+
+	/* symbol filters, symSection is resolved into symQuery for each file */
+	symQuery   elfparse.SymbolQuery
+	symSection string
+
+	/* --addr2sym queries and the --base the addresses are relative to */
+	addrs   []string
+	base    uint64
+	hasBase bool
+
+	lookups []string // --lookup names
+}
+
+func (m modes) any() bool {
+	return m.header || m.sections || m.symbols || m.relocations || m.progHeaders ||
+		m.dynamic || m.notes || m.versions || m.histogram || len(m.dumps) > 0 || len(m.addrs) > 0 || len(m.lookups) > 0
+}
+
+// dump is a -x (hex) or -p (strings) request for one section.
+type dump struct {
+	section string
+	strings bool
+}
+
+func main() {
+	c, err := parseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(os.Args[0]), err)
+		usage(os.Stderr)
+		os.Exit(1)
+	}
+	if c.help {
+		usage(os.Stdout)
+		return
+	}
+	if len(c.files) == 0 || !c.m.any() {
+		usage(os.Stderr)
+		os.Exit(1)
+	}
+
+	if err := readStdinAddrs(&c.m); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(os.Args[0]), err)
+		os.Exit(1)
+	}
+
+	files, err := expandFiles(c.files)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(os.Args[0]), err)
+		os.Exit(1)
+	}
+
+	status := 0
+	for _, bin := range files {
+		if err := dumpFile(bin, c, len(files) > 1); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", bin, err)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+// dumpFile prints the selected tables of one file. With several files,
+// the text formats start each one with a banner like readelf's.
+func dumpFile(bin string, c config, banner bool) error {
+	target, err := elfparse.OpenOptions(bin, c.opts)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	for _, w := range target.Warnings {
+		fmt.Fprintf(os.Stderr, "%s: warning: %v\n", bin, w)
+	}
+
+	m := c.m
+	if m.symSection != "" {
+		ndx, err := symSectionIndex(target, m.symSection)
+		if err != nil {
+			return err
+		}
+		m.symQuery.Sections = []uint32{ndx}
+	}
+	m.symQuery.Demangle = m.demangle
+
+	switch c.format {
+	case "json":
+		return writeJSON(os.Stdout, newReport(bin, target, m))
+	case "yaml":
+		return writeYAML(os.Stdout, newReport(bin, target, m))
+	case "llvm":
+		/* llvm-readobj names every file anyway */
+		printLLVM(os.Stdout, target, bin, m)
+		return nil
+	}
+
+	if banner {
+		fmt.Printf("\nFile: %s\n", bin)
+	}
+	if c.format == "gnu" {
+		printGNU(os.Stdout, target, m)
+	} else {
+		printModes(target, m)
+	}
+	return nil
+}
+
+func printModes(target *elfparse.ELFFile, m modes) {
+	if m.header {
+		printHeader(target.Hdr)
+	}
+
+	if m.sections {
+		printSections(target, m)
+	}
+
+	if m.symbols {
+		printSymbolTables(target, m.symQuery, m.demangle)
+	}
+
+	switch {
+	case m.relocSummary:
+		printRelocSummary(target)
+	case m.relocations:
+		printRelocations(target, m.demangle)
+	}
+
+	if m.progHeaders {
+		printProgHeaders(target)
+	}
+
+	if m.dynamic {
+		printDynamic(target, m.demangle)
+	}
+
+	if m.notes {
+		printNotes(target)
+	}
+
+	if m.versions {
+		printVersions(target, m.demangle)
+	}
+
+	if m.histogram {
+		printHashTables(target, m.demangle)
+	}
+
+	printDumps(os.Stdout, target, m.dumps)
+	printAddr2Sym(target, m)
+	printLookups(target, m)
+}
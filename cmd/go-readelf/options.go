@@ -0,0 +1,419 @@
+package main
+
+import (
+	"debug/elf"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+// config is everything the command line asks for.
+type config struct {
+	m      modes
+	opts   elfparse.Options
+	format string // "", "json", "yaml", "gnu" or "llvm"
+	help   bool
+	files  []string
+}
+
+// option is one command line flag, with its short letter (0 if it has
+// none) and long names. Options taking a value are given it in arg.
+type option struct {
+	short byte
+	long  []string
+	arg   string
+	help  string
+	set   func(c *config, val string) error
+}
+
+var options = []option{
+	{'a', []string{"all"}, "", "Equivalent to: -h -l -S -s -r -d -V -n -I", func(c *config, _ string) error {
+		c.m.header, c.m.progHeaders, c.m.sections, c.m.symbols = true, true, true, true
+		c.m.relocations, c.m.dynamic, c.m.versions, c.m.notes = true, true, true, true
+		c.m.histogram = true
+		return nil
+	}},
+	{'h', []string{"file-header"}, "", "Display the ELF file header", func(c *config, _ string) error {
+		c.m.header = true
+		return nil
+	}},
+	{'l', []string{"program-headers", "segments"}, "", "Display the program headers", func(c *config, _ string) error {
+		c.m.progHeaders = true
+		return nil
+	}},
+	{'S', []string{"section-headers", "sections"}, "", "Display the sections' headers", func(c *config, _ string) error {
+		c.m.sections = true
+		return nil
+	}},
+	{'e', []string{"headers"}, "", "Equivalent to: -h -l -S", func(c *config, _ string) error {
+		c.m.header, c.m.progHeaders, c.m.sections = true, true, true
+		return nil
+	}},
+	{'s', []string{"syms", "symbols"}, "", "Display the symbol tables", func(c *config, _ string) error {
+		c.m.symbols = true
+		return nil
+	}},
+	{0, []string{"sym-type"}, "type,...", "Only symbols of these types (func, object, ...)", func(c *config, val string) error {
+		types, err := parseNames(val, "STT_", symTypeNames)
+		for _, t := range types {
+			c.m.symQuery.Types = append(c.m.symQuery.Types, elf.SymType(t))
+		}
+		c.m.symbols = true
+		return err
+	}},
+	{0, []string{"sym-bind"}, "bind,...", "Only symbols of these bindings (local, global, weak, unique)", func(c *config, val string) error {
+		binds, err := parseNames(val, "STB_", symBindNames)
+		for _, b := range binds {
+			c.m.symQuery.Binds = append(c.m.symQuery.Binds, elf.SymBind(b))
+		}
+		c.m.symbols = true
+		return err
+	}},
+	{0, []string{"sym-visibility"}, "vis,...", "Only symbols of these visibilities (default, hidden, ...)", func(c *config, val string) error {
+		vis, err := parseNames(val, "STV_", symVisNames)
+		for _, v := range vis {
+			c.m.symQuery.Visibilities = append(c.m.symQuery.Visibilities, elf.SymVis(v))
+		}
+		c.m.symbols = true
+		return err
+	}},
+	{0, []string{"sym-section"}, "<number|name>", "Only symbols defined in this section, or UND, ABS, COM", func(c *config, val string) error {
+		c.m.symSection = val
+		c.m.symbols = true
+		return nil
+	}},
+	{0, []string{"defined"}, "", "Only defined symbols", func(c *config, _ string) error {
+		c.m.symQuery.Defined = true
+		c.m.symbols = true
+		return nil
+	}},
+	{0, []string{"undefined"}, "", "Only undefined symbols", func(c *config, _ string) error {
+		c.m.symQuery.Undefined = true
+		c.m.symbols = true
+		return nil
+	}},
+	{0, []string{"sym-name"}, "regexp", "Only symbols whose name matches", func(c *config, val string) error {
+		re, err := regexp.Compile(val)
+		if err != nil {
+			return err
+		}
+		c.m.symQuery.Name = re
+		c.m.symbols = true
+		return nil
+	}},
+	{0, []string{"sym-addr"}, "low-high", "Only symbols with low <= value < high, either may be left out", func(c *config, val string) error {
+		low, high, _ := strings.Cut(val, "-")
+		q := &c.m.symQuery
+		q.AddrRange, q.Low, q.High = true, 0, math.MaxUint64
+		var err error
+		if low != "" {
+			if q.Low, err = strconv.ParseUint(low, 0, 64); err != nil {
+				return fmt.Errorf("bad address '%s'", low)
+			}
+		}
+		if high != "" {
+			if q.High, err = strconv.ParseUint(high, 0, 64); err != nil {
+				return fmt.Errorf("bad address '%s'", high)
+			}
+		}
+		c.m.symbols = true
+		return nil
+	}},
+	{0, []string{"sort"}, "index|addr|size|name", "Sort the symbols", func(c *config, val string) error {
+		sorts := map[string]elfparse.SymbolSort{
+			"index": elfparse.SortIndex, "addr": elfparse.SortAddress, "address": elfparse.SortAddress,
+			"size": elfparse.SortSize, "name": elfparse.SortName,
+		}
+		key, ok := sorts[val]
+		if !ok {
+			return fmt.Errorf("unknown sort key '%s'", val)
+		}
+		c.m.symQuery.Sort = key
+		c.m.symbols = true
+		return nil
+	}},
+	{0, []string{"reverse"}, "", "Reverse the symbol order", func(c *config, _ string) error {
+		c.m.symQuery.Reverse = true
+		c.m.symbols = true
+		return nil
+	}},
+	{0, []string{"limit"}, "n", "Show at most n symbols per table", func(c *config, val string) error {
+		n, err := strconv.Atoi(val)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("bad limit '%s'", val)
+		}
+		c.m.symQuery.Limit = n
+		c.m.symbols = true
+		return nil
+	}},
+	{0, []string{"addr2sym"}, "addr,...|-", "Find the symbols at these hex addresses or section+offsets, - reads them from stdin", func(c *config, val string) error {
+		c.m.addrs = append(c.m.addrs, strings.Split(val, ",")...)
+		return nil
+	}},
+	{0, []string{"base"}, "addr", "The load address of the image, for --addr2sym on PIEs and libraries", func(c *config, val string) error {
+		base, err := parseHex(val)
+		c.m.base, c.m.hasBase = base, true
+		return err
+	}},
+	{0, []string{"lookup"}, "name[@version]", "Look a symbol up through the hash table like dlsym (dlvsym)", func(c *config, val string) error {
+		c.m.lookups = append(c.m.lookups, val)
+		return nil
+	}},
+	{'n', []string{"notes"}, "", "Display the notes", func(c *config, _ string) error {
+		c.m.notes = true
+		return nil
+	}},
+	{'r', []string{"relocs"}, "", "Display the relocations", func(c *config, _ string) error {
+		c.m.relocations = true
+		return nil
+	}},
+	{0, []string{"reloc-summary"}, "", "Display only how many relocations of each type there are", func(c *config, _ string) error {
+		c.m.relocations, c.m.relocSummary = true, true
+		return nil
+	}},
+	{'d', []string{"dynamic"}, "", "Display the dynamic section", func(c *config, _ string) error {
+		c.m.dynamic = true
+		return nil
+	}},
+	{'V', []string{"version-info"}, "", "Display the version sections", func(c *config, _ string) error {
+		c.m.versions = true
+		return nil
+	}},
+	{'I', []string{"histogram"}, "", "Display the hash tables' bucket list lengths", func(c *config, _ string) error {
+		c.m.histogram = true
+		return nil
+	}},
+	{'x', []string{"hex-dump"}, "<number|name>", "Dump the contents of a section as bytes", func(c *config, val string) error {
+		c.m.dumps = append(c.m.dumps, dump{val, false})
+		return nil
+	}},
+	{'p', []string{"string-dump"}, "<number|name>", "Dump the contents of a section as strings", func(c *config, val string) error {
+		c.m.dumps = append(c.m.dumps, dump{val, true})
+		return nil
+	}},
+	{'t', []string{"section-details"}, "", "Display the section details", func(c *config, _ string) error {
+		c.m.sections, c.m.sectionDetails = true, true
+		return nil
+	}},
+	{'W', []string{"wide"}, "", "Don't split lines to fit in 80 columns", func(c *config, _ string) error {
+		c.m.wide = true
+		return nil
+	}},
+	{'C', []string{"demangle"}, "", "Decode C++ and Rust symbol names", func(c *config, _ string) error {
+		c.m.demangle = true
+		return nil
+	}},
+	{'R', []string{"resilient"}, "", "Keep going on corrupted metadata", func(c *config, _ string) error {
+		c.opts.Resilient = true
+		return nil
+	}},
+	{0, []string{"json"}, "", "Print the selected tables as JSON", func(c *config, _ string) error {
+		c.format = "json"
+		return nil
+	}},
+	{0, []string{"yaml"}, "", "Print the selected tables as YAML", func(c *config, _ string) error {
+		c.format = "yaml"
+		return nil
+	}},
+	{0, []string{"compat"}, "gnu|llvm", "Print the selected tables like readelf -W or llvm-readobj", func(c *config, val string) error {
+		if val != "gnu" && val != "llvm" {
+			return fmt.Errorf("unknown --compat style '%s'", val)
+		}
+		c.format = val
+		return nil
+	}},
+	{'H', []string{"help"}, "", "Display this information", func(c *config, _ string) error {
+		c.help = true
+		return nil
+	}},
+}
+
+var symTypeNames = map[string]uint8{
+	"notype": 0, "object": 1, "func": 2, "section": 3, "file": 4, "common": 5, "tls": 6, "ifunc": 10,
+}
+
+var symBindNames = map[string]uint8{"local": 0, "global": 1, "weak": 2, "unique": 10}
+
+var symVisNames = map[string]uint8{"default": 0, "internal": 1, "hidden": 2, "protected": 3}
+
+// parseNames parses a comma separated list of symbol attributes, given by
+// name with or without their prefix ("func", "STT_FUNC") or as numbers.
+func parseNames(list, prefix string, names map[string]uint8) ([]uint8, error) {
+	var vals []uint8
+	for _, name := range strings.Split(list, ",") {
+		key := strings.ToLower(strings.TrimPrefix(strings.ToUpper(name), prefix))
+		if v, ok := names[key]; ok {
+			vals = append(vals, v)
+			continue
+		}
+		v, err := strconv.ParseUint(name, 0, 4)
+		if err != nil {
+			return nil, fmt.Errorf("unknown symbol attribute '%s'", name)
+		}
+		vals = append(vals, uint8(v))
+	}
+	return vals, nil
+}
+
+// symSectionIndex resolves --sym-section for one file.
+func symSectionIndex(elfFs *elfparse.ELFFile, spec string) (uint32, error) {
+	switch strings.ToUpper(spec) {
+	case "UND":
+		return uint32(elf.SHN_UNDEF), nil
+	case "ABS":
+		return uint32(elf.SHN_ABS), nil
+	case "COM":
+		return uint32(elf.SHN_COMMON), nil
+	}
+	return elfFs.LookupSection(spec)
+}
+
+func lookupShort(c byte) *option {
+	for i := range options {
+		if options[i].short == c {
+			return &options[i]
+		}
+	}
+	return nil
+}
+
+func lookupLong(name string) *option {
+	for i := range options {
+		for _, long := range options[i].long {
+			if long == name {
+				return &options[i]
+			}
+		}
+	}
+	return nil
+}
+
+// parseArgs parses the command line the way getopt_long does: short
+// options can be combined ("-hSl"), a value follows its letter directly
+// or as the next argument, long options take theirs after "=" or as the
+// next argument, and options and files can be mixed. "--" ends the
+// options.
+func parseArgs(args []string) (config, error) {
+	var c config
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			c.files = append(c.files, args[i+1:]...)
+			return c, nil
+
+		case strings.HasPrefix(arg, "--"):
+			name, val, hasVal := strings.Cut(arg[2:], "=")
+			o := lookupLong(name)
+			if o == nil {
+				return c, fmt.Errorf("unrecognized option '%s'", arg)
+			}
+			if o.arg == "" && hasVal {
+				return c, fmt.Errorf("option '--%s' doesn't allow an argument", name)
+			}
+			if o.arg != "" && !hasVal {
+				if i+1 >= len(args) {
+					return c, fmt.Errorf("option '--%s' requires an argument", name)
+				}
+				i++
+				val = args[i]
+			}
+			if err := o.set(&c, val); err != nil {
+				return c, err
+			}
+
+		case len(arg) > 1 && arg[0] == '-':
+			for j := 1; j < len(arg); j++ {
+				o := lookupShort(arg[j])
+				if o == nil {
+					return c, fmt.Errorf("invalid option -- '%c'", arg[j])
+				}
+				if o.arg == "" {
+					o.set(&c, "")
+					continue
+				}
+
+				/* the value is the rest of the cluster or the next argument */
+				val := arg[j+1:]
+				if val == "" {
+					if i+1 >= len(args) {
+						return c, fmt.Errorf("option requires an argument -- '%c'", arg[j])
+					}
+					i++
+					val = args[i]
+				}
+				if err := o.set(&c, val); err != nil {
+					return c, err
+				}
+				break
+			}
+
+		default:
+			c.files = append(c.files, arg)
+		}
+	}
+	return c, nil
+}
+
+// expandFiles expands the glob patterns among the files, for shells that
+// leave them alone. A name that exists is taken as is even if it looks
+// like a pattern.
+func expandFiles(files []string) ([]string, error) {
+	var out []string
+	for _, f := range files {
+		if !strings.ContainsAny(f, "*?[") {
+			out = append(out, f)
+			continue
+		}
+		if _, err := os.Stat(f); err == nil {
+			out = append(out, f)
+			continue
+		}
+		matches, err := filepath.Glob(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: no such file", f)
+		}
+		out = append(out, matches...)
+	}
+	return out, nil
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <option(s)> elf-file(s)\n", filepath.Base(os.Args[0]))
+	fmt.Fprintln(w, " Display information about the contents of ELF format files")
+	fmt.Fprintln(w, " Options are:")
+	for _, o := range options {
+		var names []string
+		if o.short != 0 {
+			name := "-" + string(o.short)
+			if o.arg != "" {
+				name += " " + o.arg
+			}
+			names = append(names, name)
+		}
+		for _, long := range o.long {
+			name := "--" + long
+			if o.arg != "" {
+				name += "=" + o.arg
+			}
+			names = append(names, name)
+		}
+
+		synopsis := "  " + strings.Join(names, ", ")
+		if len(synopsis) > 40 {
+			fmt.Fprintf(w, "%s\n  %-38s %s\n", synopsis, "", o.help)
+		} else {
+			fmt.Fprintf(w, "%-40s %s\n", synopsis, o.help)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want config
+		err  string
+	}{
+		{
+			name: "clustered short flags",
+			args: "-hSl a.out",
+			want: config{m: modes{header: true, sections: true, progHeaders: true}, files: []string{"a.out"}},
+		},
+		{
+			name: "short value in the cluster and after it",
+			args: "-Wx.text -p .comment a.out",
+			want: config{m: modes{wide: true, dumps: []dump{{".text", false}, {".comment", true}}}, files: []string{"a.out"}},
+		},
+		{
+			name: "long value after =",
+			args: "--hex-dump=.data --limit=3 a.out",
+			want: config{m: modes{dumps: []dump{{".data", false}}, symbols: true, symQuery: elfparse.SymbolQuery{Limit: 3}}, files: []string{"a.out"}},
+		},
+		{
+			name: "long value as the next argument",
+			args: "--sort name --compat llvm a.out",
+			want: config{m: modes{symbols: true, symQuery: elfparse.SymbolQuery{Sort: elfparse.SortName}}, format: "llvm", files: []string{"a.out"}},
+		},
+		{
+			name: "options after files",
+			args: "a.out -r b.out --json",
+			want: config{m: modes{relocations: true}, format: "json", files: []string{"a.out", "b.out"}},
+		},
+		{
+			name: "-- ends the options",
+			args: "-h -- -S --json",
+			want: config{m: modes{header: true}, files: []string{"-S", "--json"}},
+		},
+		{
+			name: "lone - is a file",
+			args: "-d -",
+			want: config{m: modes{dynamic: true}, files: []string{"-"}},
+		},
+		{name: "unknown long option", args: "--bogus a.out", err: "unrecognized option '--bogus'"},
+		{name: "unknown short option", args: "-hq a.out", err: "invalid option -- 'q'"},
+		{name: "long option missing its value", args: "--hex-dump", err: "option '--hex-dump' requires an argument"},
+		{name: "short option missing its value", args: "-x", err: "option requires an argument -- 'x'"},
+		{name: "value to a flag", args: "--wide=1 a.out", err: "option '--wide' doesn't allow an argument"},
+		{name: "bad value", args: "--compat=bsd a.out", err: "unknown --compat style 'bsd'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseArgs(strings.Fields(tt.args))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("parseArgs: %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c, tt.want) {
+				t.Errorf("parseArgs = %+v, want %+v", c, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.so", "b.so", "c.o", "[x].o"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+		err   bool
+	}{
+		{"plain names are kept", []string{"missing", "c.o"}, []string{"missing", "c.o"}, false},
+		{"pattern", []string{"*.so", "c.o"}, []string{"a.so", "b.so", "c.o"}, false},
+		{"existing name that looks like a pattern", []string{"[x].o"}, []string{"[x].o"}, false},
+		{"pattern matching nothing", []string{"*.a"}, nil, true},
+		{"bad pattern", []string{"[.o"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files, want []string
+			for _, f := range tt.files {
+				files = append(files, filepath.Join(dir, f))
+			}
+			for _, f := range tt.want {
+				want = append(want, filepath.Join(dir, f))
+			}
+			got, err := expandFiles(files)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expandFiles = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expandFiles = %q, want %q", got, want)
+			}
+		})
+	}
+}
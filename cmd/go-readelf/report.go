@@ -0,0 +1,663 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+// schemaVersion is bumped whenever a field of the --json/--yaml documents
+// is renamed, removed or changes meaning. Adding fields doesn't bump it.
+const schemaVersion = 1
+
+// hexAddr is an address or offset. It is written as a "0x..." string,
+// 64-bit addresses don't survive the doubles jq and JavaScript use.
+type hexAddr uint64
+
+func (a hexAddr) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"0x%x"`, uint64(a))), nil
+}
+
+type report struct {
+	SchemaVersion int                `json:"schema_version"`
+	File          string             `json:"file"`
+	Header        *headerReport      `json:"header,omitempty"`
+	Sections      *[]sectionReport   `json:"sections,omitempty"`
+	Segments      *[]segmentReport   `json:"segments,omitempty"`
+	SymbolTables  *[]symtabReport    `json:"symbol_tables,omitempty"`
+	Relocations   *[]relocTabReport  `json:"relocations,omitempty"`
+	Dynamic       *[]dynamicReport   `json:"dynamic,omitempty"`
+	Notes         *[]noteTableReport `json:"notes,omitempty"`
+	Versions      *versionsReport    `json:"versions,omitempty"`
+	HashTables    *[]hashTableReport `json:"hash_tables,omitempty"`
+	Addr2Sym      *[]addrReport      `json:"addr2sym,omitempty"`
+	Lookups       *[]lookupReport    `json:"lookups,omitempty"`
+	Warnings      []string           `json:"warnings,omitempty"`
+}
+
+type headerReport struct {
+	Class      string  `json:"class"`
+	Data       string  `json:"data"`
+	Version    string  `json:"version"`
+	OSABI      string  `json:"osabi"`
+	ABIVersion uint8   `json:"abi_version"`
+	Type       string  `json:"type"`
+	Machine    string  `json:"machine"`
+	Entry      hexAddr `json:"entry"`
+	Phoff      hexAddr `json:"phoff"`
+	Shoff      hexAddr `json:"shoff"`
+	Flags      hexAddr `json:"flags"`
+	Ehsize     uint16  `json:"ehsize"`
+	Phentsize  uint16  `json:"phentsize"`
+	Phnum      uint16  `json:"phnum"`
+	Shentsize  uint16  `json:"shentsize"`
+	Shnum      uint16  `json:"shnum"`
+	Shstrndx   uint16  `json:"shstrndx"`
+
+	/* the same with extended numbering resolved */
+	SegmentCount uint32 `json:"segment_count"`
+	SectionCount uint32 `json:"section_count"`
+	ShstrIndex   uint32 `json:"shstr_index"`
+}
+
+type sectionReport struct {
+	Index     int      `json:"index"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Flags     []string `json:"flags"`
+	Addr      hexAddr  `json:"addr"`
+	Offset    hexAddr  `json:"offset"`
+	Size      uint64   `json:"size"`
+	Entsize   uint64   `json:"entsize"`
+	Link      uint32   `json:"link"`
+	Info      uint32   `json:"info"`
+	Addralign uint64   `json:"addralign"`
+	Synthetic bool     `json:"synthetic"`
+
+	Compression *compressionReport `json:"compression,omitempty"`
+}
+
+type compressionReport struct {
+	Type             string `json:"type"`
+	UncompressedSize uint64 `json:"uncompressed_size"`
+	Addralign        uint64 `json:"addralign"`
+}
+
+type segmentReport struct {
+	Index       int      `json:"index"`
+	Type        string   `json:"type"`
+	Flags       []string `json:"flags"`
+	Offset      hexAddr  `json:"offset"`
+	Vaddr       hexAddr  `json:"vaddr"`
+	Paddr       hexAddr  `json:"paddr"`
+	Filesz      uint64   `json:"filesz"`
+	Memsz       uint64   `json:"memsz"`
+	Align       uint64   `json:"align"`
+	Sections    []string `json:"sections"`
+	Interpreter string   `json:"interpreter,omitempty"`
+}
+
+type symtabReport struct {
+	Table   string         `json:"table"`
+	Symbols []symbolReport `json:"symbols"`
+}
+
+type symbolReport struct {
+	Index      uint32  `json:"index"`
+	Name       string  `json:"name"`
+	Version    string  `json:"version,omitempty"`
+	Value      hexAddr `json:"value"`
+	Size       uint64  `json:"size"`
+	Type       string  `json:"type"`
+	Bind       string  `json:"bind"`
+	Visibility string  `json:"visibility"`
+	Shndx      uint16  `json:"shndx"`
+	Section    string  `json:"section,omitempty"`
+}
+
+// relocTabReport is a relocation section. RELR tables list the addresses
+// they relocate as RELATIVE entries, RelrWords is how many words encode
+// them. --reloc-summary leaves out the entries and gives Counts by type.
+type relocTabReport struct {
+	Section   string         `json:"section"`
+	Count     int            `json:"count"`
+	RelrWords int            `json:"relr_words,omitempty"`
+	Counts    map[string]int `json:"counts,omitempty"`
+	Entries   *[]relocReport `json:"entries,omitempty"`
+}
+
+type relocReport struct {
+	Offset      hexAddr `json:"offset"`
+	Info        hexAddr `json:"info"`
+	Type        string  `json:"type"`
+	SymbolIndex uint32  `json:"symbol_index"`
+	Symbol      string  `json:"symbol"`
+	SymbolValue hexAddr `json:"symbol_value"`
+	Addend      *int64  `json:"addend,omitempty"`
+}
+
+type dynamicReport struct {
+	Tag    hexAddr  `json:"tag"`
+	Name   string   `json:"name"`
+	Value  hexAddr  `json:"value"`
+	String string   `json:"string,omitempty"`
+	Flags  []string `json:"flags,omitempty"`
+}
+
+type noteTableReport struct {
+	Section string       `json:"section,omitempty"`
+	Offset  hexAddr      `json:"offset"`
+	Size    uint64       `json:"size"`
+	Notes   []noteReport `json:"notes"`
+}
+
+type noteReport struct {
+	Owner       string   `json:"owner"`
+	Type        uint32   `json:"type"`
+	TypeName    string   `json:"type_name"`
+	DescSize    int      `json:"desc_size"`
+	Description []string `json:"description"`
+}
+
+// addrReport is an --addr2sym query. Address is the address looked up,
+// after --base or the section offset was applied.
+type addrReport struct {
+	Query   string  `json:"query"`
+	Address hexAddr `json:"address"`
+	Symbol  string  `json:"symbol,omitempty"`
+	Table   string  `json:"table,omitempty"`
+	Index   uint32  `json:"index,omitempty"`
+	Offset  uint64  `json:"offset,omitempty"`
+	Section string  `json:"section,omitempty"`
+	Segment *int    `json:"segment,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+type versionsReport struct {
+	Definitions []verdefReport  `json:"definitions"`
+	Needs       []verneedReport `json:"needs"`
+}
+
+type verdefReport struct {
+	Index   uint16   `json:"index"`
+	Flags   string   `json:"flags"`
+	Name    string   `json:"name"`
+	Parents []string `json:"parents"`
+}
+
+type verneedReport struct {
+	File     string             `json:"file"`
+	Versions []vernauxReportRow `json:"versions"`
+}
+
+type vernauxReportRow struct {
+	Name  string `json:"name"`
+	Flags string `json:"flags"`
+	Index uint16 `json:"index"`
+}
+
+// hashTableReport is a .hash or .gnu.hash table. Histogram[n] is the
+// number of buckets whose chain is n symbols long.
+type hashTableReport struct {
+	Section    string              `json:"section"`
+	Type       string              `json:"type"`
+	Buckets    int                 `json:"buckets"`
+	Chains     int                 `json:"chains"`
+	SymOffset  *uint32             `json:"symoffset,omitempty"`
+	BloomWords *int                `json:"bloom_words,omitempty"`
+	BloomShift *uint32             `json:"bloom_shift,omitempty"`
+	Histogram  []int               `json:"histogram"`
+	Problems   []hashProblemReport `json:"problems"`
+}
+
+type hashProblemReport struct {
+	Index  uint32 `json:"index"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// lookupReport is a --lookup query. Symbol is the entry found, Reason
+// says why there is none.
+type lookupReport struct {
+	Query      string            `json:"query"`
+	Table      string            `json:"table,omitempty"`
+	Hash       *hexAddr          `json:"hash,omitempty"`
+	Bucket     *int              `json:"bucket,omitempty"`
+	Candidates []candidateReport `json:"candidates"`
+	Symbol     *symbolReport     `json:"symbol,omitempty"`
+	Reason     string            `json:"reason,omitempty"`
+}
+
+type candidateReport struct {
+	Index  uint32 `json:"index"`
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// flagList splits debug/elf's "A+B" flag strings, an empty mask is [].
+func flagList(val uint64, s fmt.Stringer) []string {
+	if val == 0 {
+		return []string{}
+	}
+	return strings.Split(s.String(), "+")
+}
+
+func newReport(file string, elfFs *elfparse.ELFFile, m modes) *report {
+	r := &report{SchemaVersion: schemaVersion, File: file}
+	for _, w := range elfFs.Warnings {
+		r.Warnings = append(r.Warnings, w.Error())
+	}
+
+	if m.header {
+		h := elfFs.Hdr
+		r.Header = &headerReport{
+			Class:      h.Class.String(),
+			Data:       elf.Data(h.Ident[elf.EI_DATA]).String(),
+			Version:    h.Version.String(),
+			OSABI:      elf.OSABI(h.Ident[elf.EI_OSABI]).String(),
+			ABIVersion: h.Ident[elf.EI_ABIVERSION],
+			Type:       h.Type.String(),
+			Machine:    h.Machine.String(),
+			Entry:      hexAddr(h.Entry),
+			Phoff:      hexAddr(h.Phoff),
+			Shoff:      hexAddr(h.Shoff),
+			Flags:      hexAddr(h.Flags),
+			Ehsize:     h.Ehsize,
+			Phentsize:  h.Phentsize,
+			Phnum:      h.Phnum,
+			Shentsize:  h.Shentsize,
+			Shnum:      h.Shnum,
+			Shstrndx:   h.Shstrndx,
+
+			SegmentCount: h.SegmentCount,
+			SectionCount: h.SectionCount,
+			ShstrIndex:   h.ShstrIndex,
+		}
+	}
+
+	if m.sections {
+		sections := []sectionReport{}
+		for i, s := range elfFs.ElfSections.Section {
+			var compression *compressionReport
+			if chdr, ok := elfFs.Compression(uint32(i)); ok {
+				compression = &compressionReport{compressionName(chdr.Type), chdr.Size, chdr.Addralign}
+			}
+			sections = append(sections, sectionReport{
+				Index:     i,
+				Name:      elfFs.ElfSections.SectionName[i],
+				Type:      s.Type.String(),
+				Flags:     flagList(uint64(s.Flags), s.Flags),
+				Addr:      hexAddr(s.Addr),
+				Offset:    hexAddr(s.Off),
+				Size:      s.Size,
+				Entsize:   s.Entsize,
+				Link:      s.Link,
+				Info:      s.Info,
+				Addralign: s.Addralign,
+				Synthetic: s.Synthetic,
+
+				Compression: compression,
+			})
+		}
+		r.Sections = &sections
+	}
+
+	if m.progHeaders {
+		segments := []segmentReport{}
+		for i, p := range elfFs.ProgHeaders {
+			seg := segmentReport{
+				Index:    i,
+				Type:     elfFs.ProgTypeName(p.Type),
+				Flags:    flagList(uint64(p.Flags), p.Flags),
+				Offset:   hexAddr(p.Off),
+				Vaddr:    hexAddr(p.Vaddr),
+				Paddr:    hexAddr(p.Paddr),
+				Filesz:   p.Filesz,
+				Memsz:    p.Memsz,
+				Align:    p.Align,
+				Sections: []string{},
+			}
+			for _, sNdx := range elfFs.SegmentSections(i) {
+				seg.Sections = append(seg.Sections, elfFs.ElfSections.SectionName[sNdx])
+			}
+			if p.Type == elf.PT_INTERP {
+				seg.Interpreter, _ = elfFs.Interp()
+			}
+			segments = append(segments, seg)
+		}
+		r.Segments = &segments
+	}
+
+	if m.symbols {
+		tables := []symtabReport{}
+		for _, t := range []struct {
+			name    string
+			symType int
+		}{
+			{".dynsym", elfparse.DynSym},
+			{".symtab", elfparse.Sym},
+		} {
+			if len(elfFs.SymbolTable(t.symType)) == 0 {
+				continue
+			}
+			table := symtabReport{Table: t.name, Symbols: []symbolReport{}}
+			for _, sym := range elfFs.QuerySymbols(t.symType, m.symQuery) {
+				table.Symbols = append(table.Symbols, newSymbolReport(elfFs, sym, t.symType, m.demangle))
+			}
+			tables = append(tables, table)
+		}
+		r.SymbolTables = &tables
+	}
+
+	if m.relocations {
+		tables := []relocTabReport{}
+		for k := range elfFs.ElfSections.Section {
+			rels, ok := elfFs.Rels[uint32(k)]
+			relr, isRelr := elfFs.Relrs[uint32(k)]
+			if !ok && !isRelr {
+				continue
+			}
+			table := relocTabReport{Section: elfFs.ElfSections.SectionName[k], Count: len(rels)}
+			if isRelr {
+				table.Count, table.RelrWords = len(relr.Addrs), relr.Words
+			}
+			if m.relocSummary {
+				table.Counts = map[string]int{}
+				for _, c := range relocCounts(elfFs, uint32(k)) {
+					table.Counts[c.name] = c.n
+				}
+				tables = append(tables, table)
+				continue
+			}
+
+			entries := []relocReport{}
+			relType, _ := elfFs.RelativeType()
+			for _, addr := range relr.Addrs {
+				entries = append(entries, relocReport{
+					Offset: hexAddr(addr),
+					Info:   hexAddr(relType),
+					Type:   resolveRelocType(relType, elfFs.FileHdr.Machine),
+				})
+			}
+			/* a bad sh_link fails every entry the same way, warn once */
+			failed := map[string]bool{}
+			for _, rel := range rels {
+				symbol, err := elfFs.RelocSymbol(uint32(k), rel)
+				if err != nil && !failed[err.Error()] {
+					failed[err.Error()] = true
+					r.Warnings = append(r.Warnings, err.Error())
+				}
+				row := relocReport{
+					Offset:      hexAddr(rel.Off),
+					Info:        hexAddr(rel.Info),
+					Type:        resolveRelocType(rel.Type, elfFs.FileHdr.Machine),
+					SymbolIndex: rel.Sym,
+					Symbol:      displayName(symbol.Name, m.demangle),
+					SymbolValue: hexAddr(symbol.Value),
+				}
+				if rel.HasAddend {
+					addend := rel.Addend
+					row.Addend = &addend
+				}
+				entries = append(entries, row)
+			}
+			table.Entries = &entries
+			tables = append(tables, table)
+		}
+		r.Relocations = &tables
+	}
+
+	if m.dynamic {
+		entries := []dynamicReport{}
+		for _, entry := range elfFs.Dynamic {
+			row := dynamicReport{Tag: hexAddr(entry.Tag), Name: elfFs.DynTagName(entry.Tag), Value: hexAddr(entry.Val)}
+			switch elfFs.DynTagKind(entry.Tag) {
+			case elfparse.DynKindString:
+				str, _ := elfFs.DynString(entry)
+				row.String = displayName(str, m.demangle)
+			case elfparse.DynKindFlags:
+				row.Flags = elfparse.DynFlagNames(entry.Tag, entry.Val)
+			}
+			entries = append(entries, row)
+		}
+		r.Dynamic = &entries
+	}
+
+	if m.notes {
+		tables := []noteTableReport{}
+		for _, t := range elfFs.Notes {
+			table := noteTableReport{Section: t.Name, Offset: hexAddr(t.Off), Size: t.Size, Notes: []noteReport{}}
+			for _, n := range t.Notes {
+				desc := elfFs.NoteDescription(n)
+				if desc == nil {
+					desc = []string{}
+				}
+				table.Notes = append(table.Notes, noteReport{
+					Owner:       n.Owner,
+					Type:        n.Type,
+					TypeName:    elfFs.NoteTypeName(n),
+					DescSize:    len(n.Desc),
+					Description: desc,
+				})
+			}
+			tables = append(tables, table)
+		}
+		r.Notes = &tables
+	}
+
+	if m.versions {
+		v := &versionsReport{Definitions: []verdefReport{}, Needs: []verneedReport{}}
+		for _, def := range elfFs.Verdef {
+			row := verdefReport{Index: def.Ndx, Flags: verFlags(def.Flags), Parents: []string{}}
+			if len(def.Names) > 0 {
+				row.Name = displayName(def.Names[0], m.demangle)
+				for _, parent := range def.Names[1:] {
+					row.Parents = append(row.Parents, displayName(parent, m.demangle))
+				}
+			}
+			v.Definitions = append(v.Definitions, row)
+		}
+		for _, need := range elfFs.Verneed {
+			row := verneedReport{File: need.File, Versions: []vernauxReportRow{}}
+			for _, aux := range need.Aux {
+				row.Versions = append(row.Versions, vernauxReportRow{Name: displayName(aux.Name, m.demangle), Flags: verFlags(aux.Flags), Index: aux.Other})
+			}
+			v.Needs = append(v.Needs, row)
+		}
+		r.Versions = v
+	}
+
+	if m.histogram {
+		tables := []hashTableReport{}
+		for _, h := range hashTables(elfFs) {
+			counts, _ := histogram(h)
+			row := hashTableReport{
+				Section:   elfFs.ElfSections.SectionName[h.Section],
+				Type:      strings.ToLower(hashKind(h)),
+				Buckets:   len(h.Buckets),
+				Chains:    len(h.Chains),
+				Histogram: counts,
+				Problems:  []hashProblemReport{},
+			}
+			if h.GNU {
+				bloomWords := len(h.Bloom)
+				row.SymOffset, row.BloomWords, row.BloomShift = &h.SymOffset, &bloomWords, &h.BloomShift
+			}
+			for _, p := range elfFs.CheckHashTable(h) {
+				row.Problems = append(row.Problems, hashProblemReport{p.Symbol, displayName(p.Name, m.demangle), p.Reason})
+			}
+			tables = append(tables, row)
+		}
+		r.HashTables = &tables
+	}
+
+	if len(m.addrs) > 0 {
+		lookups := []addrReport{}
+		for _, l := range lookupAddrs(elfFs, m) {
+			row := addrReport{Query: l.query, Address: hexAddr(l.info.Addr)}
+			if l.err != nil {
+				row.Error = l.err.Error()
+				lookups = append(lookups, row)
+				continue
+			}
+			if l.info.HasSymbol {
+				row.Symbol, row.Index, row.Offset = displayName(l.info.Name, m.demangle), l.info.SymNdx, l.info.Offset
+				row.Table = ".symtab"
+				if l.info.SymType == elfparse.DynSym {
+					row.Table = ".dynsym"
+				}
+			}
+			row.Section = addrSection(elfFs, l.info)
+			if seg := l.info.Segment; seg >= 0 {
+				row.Segment = &seg
+			}
+			lookups = append(lookups, row)
+		}
+		r.Addr2Sym = &lookups
+	}
+
+	if len(m.lookups) > 0 {
+		rows := []lookupReport{}
+		lookups, err := symbolLookups(elfFs, m.lookups)
+		if err != nil {
+			for _, query := range m.lookups {
+				rows = append(rows, lookupReport{Query: query, Candidates: []candidateReport{}, Reason: err.Error()})
+			}
+		}
+		for i, l := range lookups {
+			hash, bucket := hexAddr(l.Hash), l.Bucket
+			row := lookupReport{
+				Query:      m.lookups[i],
+				Table:      elfFs.ElfSections.SectionName[l.Table.Section],
+				Hash:       &hash,
+				Candidates: []candidateReport{},
+				Reason:     l.Reason,
+			}
+			if bucket >= 0 {
+				row.Bucket = &bucket
+			}
+			for _, c := range l.Candidates {
+				row.Candidates = append(row.Candidates, candidateReport{c.Symbol.Index, displayName(c.Symbol.Name, m.demangle), c.Reason})
+			}
+			if l.Found {
+				sym := newSymbolReport(elfFs, l.Symbol, elfparse.DynSym, m.demangle)
+				row.Symbol = &sym
+			}
+			rows = append(rows, row)
+		}
+		r.Lookups = &rows
+	}
+	return r
+}
+
+func newSymbolReport(elfFs *elfparse.ELFFile, sym elfparse.Symbol, symType int, demangle bool) symbolReport {
+	row := symbolReport{
+		Index:      sym.Index,
+		Name:       displayName(sym.Name, demangle),
+		Value:      hexAddr(sym.Value),
+		Size:       sym.Size,
+		Type:       sym.Type().String(),
+		Bind:       sym.Bind().String(),
+		Visibility: sym.Visibility().String(),
+		Shndx:      sym.Shndx,
+		Section:    sym.SectionName,
+	}
+	if symType == elfparse.DynSym {
+		row.Version, _, _ = elfFs.SymbolVersion(sym.Index)
+	}
+	return row
+}
+
+func writeJSON(w io.Writer, r *report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// writeYAML emits the same document as writeJSON. It walks the JSON token
+// stream rather than the structs so both formats share one schema and keep
+// the field order.
+func writeYAML(w io.Writer, r *report) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	if err := yamlValue(&b, dec, 0, false); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// yamlValue writes the next JSON value of dec in block style. The value
+// follows a "key:" or "-" already on the current line; a map that is a
+// list item starts on that line, "- key: value", like hand written YAML.
+func yamlValue(b *strings.Builder, dec *json.Decoder, indent int, listItem bool) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	pad := strings.Repeat("  ", indent)
+
+	switch t := tok.(type) {
+	case json.Delim:
+		empty := !dec.More()
+		switch {
+		case t == '{' && empty:
+			b.WriteString(" {}\n")
+		case t == '[' && empty:
+			b.WriteString(" []\n")
+		case t == '{' && listItem:
+		case indent > 0:
+			b.WriteString("\n")
+		}
+
+		first := true
+		for dec.More() {
+			lead := pad
+			if first && t == '{' && listItem {
+				lead = " "
+			}
+			first = false
+
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				b.WriteString(lead + key.(string) + ":")
+			} else {
+				b.WriteString(lead + "-")
+			}
+			if err := yamlValue(b, dec, indent+1, t == '['); err != nil {
+				return err
+			}
+		}
+		_, err := dec.Token() // closing delimiter
+		return err
+
+	case string:
+		b.WriteString(" " + yamlString(t) + "\n")
+	case json.Number:
+		b.WriteString(" " + t.String() + "\n")
+	case bool:
+		b.WriteString(fmt.Sprintf(" %t\n", t))
+	case nil:
+		b.WriteString(" null\n")
+	}
+	return nil
+}
+
+func yamlString(s string) string {
+	return fmt.Sprintf("%q", s)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sad0p/go-readelf/elfparse"
+)
+
+// testdata/v.so.json is the schema_version 1 document of --json -a, any
+// change to it must be an added field or come with a new schema version.
+// --yaml is the same document.
+func TestReportSchema(t *testing.T) {
+	c, err := parseArgs([]string{"--json", "-a", "../../elfparse/testdata/v.so"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	elfFs, err := elfparse.OpenOptions(c.files[0], c.opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer elfFs.Close()
+	r := newReport(c.files[0], elfFs, c.m)
+
+	var jsonDoc, yamlDoc bytes.Buffer
+	if err := writeJSON(&jsonDoc, r); err != nil {
+		t.Fatal(err)
+	}
+	compareGolden(t, jsonDoc.String(), "testdata/v.so.json")
+
+	if err := writeYAML(&yamlDoc, r); err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON, fromYAML any
+	if err := json.Unmarshal(jsonDoc.Bytes(), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(yamlDoc.Bytes(), &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	/* YAML decodes integers as int, JSON as float64 */
+	data, err := json.Marshal(fromYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromYAML = nil
+	if err := json.Unmarshal(data, &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("--yaml decodes to\n%s\nnot the --json document", data)
+	}
+}
+
+// A relocation whose symbol can't be found still gets its entry, with
+// the reason among the warnings.
+func TestReportRelocSymbolWarning(t *testing.T) {
+	data, err := os.ReadFile("../../elfparse/testdata/v.so")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	/* point the first entry of .rela.dyn with a symbol past the end of .dynsym */
+	rela := f.Section(".rela.dyn")
+	for off := rela.Offset; off < rela.Offset+rela.Size; off += 24 {
+		if info := binary.LittleEndian.Uint64(data[off+8:]); info>>32 != 0 {
+			binary.LittleEndian.PutUint64(data[off+8:], 0xffff<<32|info&0xffffffff)
+			break
+		}
+	}
+
+	elfFs, err := elfparse.NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newReport("v.so", elfFs, modes{relocations: true})
+
+	found := false
+	for _, table := range *r.Relocations {
+		for _, rel := range *table.Entries {
+			found = found || rel.SymbolIndex == 0xffff && rel.Symbol == ""
+		}
+	}
+	if !found {
+		t.Errorf("no entry for the relocation against symbol 0xffff")
+	}
+	if len(r.Warnings) != 1 || !strings.Contains(r.Warnings[0], "symbol index 65535") {
+		t.Errorf("warnings %q, want the bad symbol index", r.Warnings)
+	}
+}
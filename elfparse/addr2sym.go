@@ -0,0 +1,197 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"math"
+	"sort"
+)
+
+// AddrInfo is what an address resolves to. In relocatable files addresses
+// are offsets into Section, as symbol values are.
+type AddrInfo struct {
+	Addr uint64
+
+	HasSymbol bool
+	SymType   int    // Sym or DynSym, the table Symbol came from
+	SymNdx    uint32 // index of Symbol in that table
+	Symbol    Symbol
+	Name      string
+	Offset    uint64 // Addr - Symbol.Value
+
+	HasSection bool
+	Section    uint32
+
+	Segment int // index of the PT_LOAD segment in ProgHeaders, -1 if none
+}
+
+// symRange is one entry of the address index, [start, end) of a symbol.
+// maxEnd is the largest end of this entry and all those before it, so a
+// backwards scan for the symbols containing an address can stop early.
+type symRange struct {
+	start, end, maxEnd uint64
+	shndx              uint32
+	symType            int
+	ndx                uint32
+	rank               int
+}
+
+// Addr2Sym resolves a virtual address of an executable or shared object,
+// as the file was linked: subtract the load bias of a PIE first. The
+// symbol is the innermost one containing addr or, failing that, the
+// symbol before it in the same section if that has no size, the way
+// hand-written assembly is usually labelled.
+func (elfFs *ELFFile) Addr2Sym(addr uint64) AddrInfo {
+	info := AddrInfo{Addr: addr, Segment: elfFs.loadSegment(addr)}
+	if elfFs.Hdr.Type == elf.ET_REL {
+		/* every section starts at 0, so a bare address names no section */
+		elfFs.lookupSymbol(&info, -1)
+		return info
+	}
+
+	for i, sec := range elfFs.ElfSections.Section {
+		if sec.Flags&elf.SHF_ALLOC == 0 || sec.Type == elf.SHT_NULL || sec.Size == 0 {
+			continue
+		}
+		/* .tbss takes no room in the address space */
+		if sec.Flags&elf.SHF_TLS != 0 && sec.Type == elf.SHT_NOBITS {
+			continue
+		}
+		if addr >= sec.Addr && addr-sec.Addr < sec.Size {
+			info.HasSection, info.Section = true, uint32(i)
+			elfFs.lookupSymbol(&info, i)
+			return info
+		}
+	}
+	elfFs.lookupSymbol(&info, -1)
+	return info
+}
+
+// Addr2SymIn resolves an offset into section ndx. This is the only way to
+// name a location in a relocatable file unambiguously.
+func (elfFs *ELFFile) Addr2SymIn(ndx uint32, off uint64) AddrInfo {
+	info := AddrInfo{Addr: off, Segment: -1, HasSection: true, Section: ndx}
+	if ndx < uint32(len(elfFs.ElfSections.Section)) && elfFs.Hdr.Type != elf.ET_REL {
+		info.Addr = elfFs.ElfSections.Section[ndx].Addr + off
+		info.Segment = elfFs.loadSegment(info.Addr)
+	}
+	elfFs.lookupSymbol(&info, int(ndx))
+	return info
+}
+
+func (elfFs *ELFFile) loadSegment(addr uint64) int {
+	for i, seg := range elfFs.ProgHeaders {
+		if seg.Type == elf.PT_LOAD && addr >= seg.Vaddr && addr-seg.Vaddr < seg.Memsz {
+			return i
+		}
+	}
+	return -1
+}
+
+// lookupSymbol fills in the symbol of info.Addr, limited to the symbols
+// of section shndx unless that is -1.
+func (elfFs *ELFFile) lookupSymbol(info *AddrInfo, shndx int) {
+	index := elfFs.symbolIndex()
+	addr := info.Addr
+
+	/* walk back from the last entry starting at or before addr */
+	i := sort.Search(len(index), func(i int) bool { return index[i].start > addr })
+	var nearest *symRange
+	for j := i - 1; j >= 0 && (index[j].maxEnd > addr || nearest == nil); j-- {
+		r := &index[j]
+		if shndx >= 0 && int(r.shndx) != shndx {
+			continue
+		}
+		if addr < r.end {
+			elfFs.setSymbol(info, r)
+			return
+		}
+		if nearest == nil {
+			nearest = r
+		}
+	}
+
+	/* without a section there is no telling where an unsized symbol ends */
+	if nearest != nil && nearest.start == nearest.end && shndx >= 0 {
+		elfFs.setSymbol(info, nearest)
+	}
+}
+
+func (elfFs *ELFFile) setSymbol(info *AddrInfo, r *symRange) {
+	sym := elfFs.SymbolTable(r.symType)[r.ndx]
+	info.HasSymbol, info.SymType, info.SymNdx = true, r.symType, r.ndx
+	info.Symbol, info.Name, info.Offset = sym, sym.Name, info.Addr-sym.Value
+	if !info.HasSection {
+		info.HasSection, info.Section = true, sym.Section
+	}
+}
+
+// symbolIndex builds the address index on first use, once even when
+// Addr2Sym runs concurrently on the same file. It holds the named
+// code and data symbols of both tables that are defined in a section.
+// Entries are sorted by start address and, among equal starts, with the
+// best name last: sized over unsized, functions and objects over untyped
+// labels, global over weak over local, .symtab over .dynsym.
+func (elfFs *ELFFile) symbolIndex() []symRange {
+	elfFs.addrOnce.Do(elfFs.buildSymbolIndex)
+	return elfFs.addrIndex
+}
+
+func (elfFs *ELFFile) buildSymbolIndex() {
+	index := []symRange{}
+	for _, symType := range []int{DynSym, Sym} {
+		for _, sym := range elfFs.SymbolTable(symType) {
+			switch sym.Type() {
+			case elf.STT_NOTYPE, elf.STT_OBJECT, elf.STT_FUNC, elf.SymType(10): // STT_GNU_IFUNC
+			default:
+				continue
+			}
+			if sym.Name == "" || sym.Section == uint32(elf.SHN_UNDEF) {
+				continue
+			}
+			if sym.Shndx >= uint16(elf.SHN_LORESERVE) && sym.Shndx != uint16(elf.SHN_XINDEX) {
+				continue
+			}
+
+			rank := 0
+			if sym.Size != 0 {
+				rank += 8
+			}
+			if sym.Type() != elf.STT_NOTYPE {
+				rank += 4
+			}
+			switch sym.Bind() {
+			case elf.STB_GLOBAL:
+				rank += 2
+			case elf.STB_WEAK:
+				rank++
+			}
+			rank *= 2
+			if symType == Sym {
+				rank++
+			}
+
+			end := sym.Value + sym.Size
+			if end < sym.Value {
+				end = math.MaxUint64
+			}
+			index = append(index, symRange{
+				start: sym.Value, end: end,
+				shndx: sym.Section, symType: symType, ndx: sym.Index, rank: rank,
+			})
+		}
+	}
+
+	sort.SliceStable(index, func(i, j int) bool {
+		if index[i].start != index[j].start {
+			return index[i].start < index[j].start
+		}
+		return index[i].rank < index[j].rank
+	})
+	var maxEnd uint64
+	for i := range index {
+		maxEnd = max(maxEnd, index[i].end)
+		index[i].maxEnd = maxEnd
+	}
+
+	elfFs.addrIndex = index
+}
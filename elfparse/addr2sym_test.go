@@ -0,0 +1,53 @@
+package elfparse
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAddr2Sym(t *testing.T) {
+	elfFs, err := Open("testdata/v.so")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer elfFs.Close()
+
+	tests := []struct {
+		addr   uint64
+		name   string
+		offset uint64
+	}{
+		{0x10f9, "foo", 0},
+		{0x1100, "foo", 7},
+		{0x1104, "foo_old", 0},
+		{0x110e, "foo_old", 10},
+	}
+	for _, tt := range tests {
+		info := elfFs.Addr2Sym(tt.addr)
+		if !info.HasSymbol || info.Name != tt.name || info.Offset != tt.offset {
+			t.Errorf("Addr2Sym(0x%x) = %s+%d, want %s+%d", tt.addr, info.Name, info.Offset, tt.name, tt.offset)
+		}
+	}
+}
+
+// TestAddr2SymConcurrent is for go test -race: the first lookups of a file
+// build its address index.
+func TestAddr2SymConcurrent(t *testing.T) {
+	elfFs, err := Open("testdata/v.so")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer elfFs.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if info := elfFs.Addr2Sym(0x1104); info.Name != "foo_old" {
+				t.Errorf("Addr2Sym(0x1104) = %q, want foo_old", info.Name)
+			}
+		}()
+	}
+	wg.Wait()
+}
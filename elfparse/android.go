@@ -0,0 +1,196 @@
+package elfparse
+
+import (
+	"bytes"
+	"debug/elf"
+)
+
+// Android's packed relocation sections, written by lld and the NDK
+// relocation packer for --pack-dyn-relocs=android. SHT_ANDROID_RELR is the
+// RELR format under the type number used before SHT_RELR existed.
+const (
+	SHT_ANDROID_REL  elf.SectionType = 0x60000001
+	SHT_ANDROID_RELA elf.SectionType = 0x60000002
+	SHT_ANDROID_RELR elf.SectionType = 0x6fffff00
+)
+
+/* group flags of the APS2 format */
+const (
+	aps2GroupedByInfo        = 1
+	aps2GroupedByOffsetDelta = 2
+	aps2GroupedByAddend      = 4
+	aps2GroupHasAddend       = 8
+)
+
+// HasAddends tells whether the relocations of a section of this type carry
+// an addend, SHT_RELA and SHT_ANDROID_RELA.
+func HasAddends(t elf.SectionType) bool {
+	return t == elf.SHT_RELA || t == SHT_ANDROID_RELA
+}
+
+// slebReader reads the SLEB128 numbers an APS2 stream is made of.
+type slebReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *slebReader) next() (int64, bool) {
+	var v int64
+	for shift := uint(0); r.pos < len(r.data); shift += 7 {
+		b := r.data[r.pos]
+		r.pos++
+		if shift < 64 {
+			v |= int64(b&0x7f) << shift
+		}
+		if b&0x80 == 0 {
+			if shift+7 < 64 && b&0x40 != 0 {
+				v |= -1 << (shift + 7)
+			}
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// loadAndroidRelocations expands a SHT_ANDROID_REL or SHT_ANDROID_RELA
+// section. After the "APS2" magic come the relocation count and the
+// starting offset, then groups: a size, flags and the offset delta, info
+// and addend the group's relocations share, followed by what each of them
+// doesn't share. Offsets and addends are deltas from the previous
+// relocation, all of it SLEB128 and wrapping at the word size.
+func (elfFs *ELFFile) loadAndroidRelocations(sNdx uint32) ([]Reloc, error) {
+	sec := elfFs.ElfSections.Section[sNdx]
+	isRela := sec.Type == SHT_ANDROID_RELA
+	structure := "android relocation table " + elfFs.ElfSections.SectionName[sNdx]
+
+	data, err := elfFs.sectionTable(structure, sNdx)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte("APS2")) {
+		return nil, newFormatError(ErrBadPacking, sec.Off, structure, "no APS2 magic")
+	}
+
+	r := &slebReader{data: data, pos: 4}
+	truncated := func() error {
+		return newFormatError(ErrTruncated, sec.Off+uint64(r.pos), structure, "%d bytes", len(data))
+	}
+	count, ok := r.next()
+	if !ok {
+		return nil, truncated()
+	}
+	if count < 0 || uint64(count) > elfFs.maxRelocations() {
+		return nil, newFormatError(ErrBadPacking, sec.Off, structure, "%d relocations", count)
+	}
+	start, ok := r.next()
+	if !ok {
+		return nil, truncated()
+	}
+
+	wordMask := ^uint64(0)
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		wordMask = 0xffffffff
+	}
+	off := uint64(start) & wordMask
+	var info uint64
+	var addend int64
+
+	rels := make([]Reloc, 0, min(count, int64(len(data))))
+	for int64(len(rels)) < count {
+		size, ok := r.next()
+		if !ok {
+			return nil, truncated()
+		}
+		flags, ok := r.next()
+		if !ok {
+			return nil, truncated()
+		}
+		if size <= 0 || size > count-int64(len(rels)) {
+			return nil, newFormatError(ErrBadPacking, sec.Off+uint64(r.pos), structure,
+				"group of %d with %d of %d relocations left", size, count-int64(len(rels)), count)
+		}
+		hasAddend := flags&aps2GroupHasAddend != 0
+		if hasAddend && !isRela {
+			return nil, newFormatError(ErrBadPacking, sec.Off+uint64(r.pos), structure, "addends in a SHT_ANDROID_REL table")
+		}
+
+		/* the shared fields are read in this order, before the first relocation */
+		var delta int64
+		if flags&aps2GroupedByOffsetDelta != 0 {
+			if delta, ok = r.next(); !ok {
+				return nil, truncated()
+			}
+		}
+		if flags&aps2GroupedByInfo != 0 {
+			v, ok := r.next()
+			if !ok {
+				return nil, truncated()
+			}
+			info = uint64(v) & wordMask
+		}
+		if hasAddend && flags&aps2GroupedByAddend != 0 {
+			v, ok := r.next()
+			if !ok {
+				return nil, truncated()
+			}
+			addend += v
+		}
+		if !hasAddend {
+			addend = 0
+		}
+
+		for i := int64(0); i < size; i++ {
+			if flags&aps2GroupedByOffsetDelta == 0 {
+				if delta, ok = r.next(); !ok {
+					return nil, truncated()
+				}
+			}
+			off = (off + uint64(delta)) & wordMask
+			if flags&aps2GroupedByInfo == 0 {
+				v, ok := r.next()
+				if !ok {
+					return nil, truncated()
+				}
+				info = uint64(v) & wordMask
+			}
+			if hasAddend && flags&aps2GroupedByAddend == 0 {
+				v, ok := r.next()
+				if !ok {
+					return nil, truncated()
+				}
+				addend += v
+			}
+			rels = append(rels, elfFs.packedReloc(off, info, addend, isRela))
+		}
+	}
+	return rels, nil
+}
+
+// packedReloc builds the Reloc an unpacked Elf_Rel or Elf_Rela would
+// have given.
+func (elfFs *ELFFile) packedReloc(off, info uint64, addend int64, isRela bool) Reloc {
+	rel := Reloc{Off: off, Info: info, HasAddend: isRela}
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		rel.Sym, rel.Type = elf.R_SYM32(uint32(info)), elf.R_TYPE32(uint32(info))
+		addend = int64(int32(addend))
+	} else {
+		rel.Sym, rel.Type = elf.R_SYM64(info), elf.R_TYPE64(info)
+	}
+	if isRela {
+		rel.Addend = addend
+	}
+	return rel
+}
+
+// maxRelocations bounds the count an APS2 header may claim, since a group
+// can hold any number of relocations in a few bytes: each one patches a
+// word of what is loaded from the file.
+func (elfFs *ELFFile) maxRelocations() uint64 {
+	span := uint64(max(elfFs.Size, 0))
+	for _, seg := range elfFs.ProgHeaders {
+		if seg.Type == elf.PT_LOAD {
+			span = max(span, seg.Filesz)
+		}
+	}
+	return span / 4
+}
@@ -0,0 +1,241 @@
+package elfparse
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+)
+
+// aps2 encodes the header and the SLEB128 values of an APS2 stream.
+func aps2(vals ...int64) []byte {
+	out := []byte("APS2")
+	for _, v := range vals {
+		for {
+			b := byte(v & 0x7f)
+			v >>= 7
+			if v == 0 && b&0x40 == 0 || v == -1 && b&0x40 != 0 {
+				out = append(out, b)
+				break
+			}
+			out = append(out, b|0x80)
+		}
+	}
+	return out
+}
+
+// tableFile is an ELFFile holding data as its only section, of type typ.
+func tableFile(class elf.Class, typ elf.SectionType, data []byte) *ELFFile {
+	return &ELFFile{
+		Fh:          bytes.NewReader(data),
+		Size:        int64(len(data)),
+		FileHdr:     EnumIdent{Endianness: binary.LittleEndian, Arch: class},
+		ProgHeaders: []Segment{{Type: elf.PT_LOAD, Filesz: 1 << 20}},
+		ElfSections: SHDRTable{
+			Section:     []Section{{}, {Type: typ, Size: uint64(len(data))}},
+			SectionName: []string{"", ".rela.dyn"},
+		},
+	}
+}
+
+func TestLoadAndroidRelocations(t *testing.T) {
+	/* bionic's RELOCATION_GROUPED_BY_INFO_FLAG and friends */
+	const (
+		byInfo   = 1
+		byDelta  = 2
+		byAddend = 4
+		addend   = 8
+		relative = 8 // R_X86_64_RELATIVE, R_386_RELATIVE
+	)
+	type rel struct {
+		off, info uint64
+		addend    int64
+	}
+	tests := []struct {
+		name  string
+		class elf.Class
+		typ   elf.SectionType
+		data  []byte
+		want  []rel
+		err   error
+	}{
+		{
+			name:  "grouped by info",
+			class: elf.ELFCLASS64, typ: SHT_ANDROID_RELA,
+			data: aps2(3, 0x1000, 3, byInfo|addend, relative, 8, 0x10, 8, 4, 0x10, -8),
+			want: []rel{{0x1008, relative, 0x10}, {0x1010, relative, 0x14}, {0x1020, relative, 0xc}},
+		},
+		{
+			name:  "grouped by offset delta",
+			class: elf.ELFCLASS64, typ: SHT_ANDROID_RELA,
+			data: aps2(2, 0, 2, byDelta|addend, 8, 1<<32|1, 5, 2<<32|1, -5),
+			want: []rel{{8, 1<<32 | 1, 5}, {16, 2<<32 | 1, 0}},
+		},
+		{
+			name:  "grouped by addend",
+			class: elf.ELFCLASS64, typ: SHT_ANDROID_RELA,
+			data: aps2(2, 0x100, 2, byInfo|byAddend|addend, relative, 0x40, 8, 8),
+			want: []rel{{0x108, relative, 0x40}, {0x110, relative, 0x40}},
+		},
+		{
+			name:  "group without addends",
+			class: elf.ELFCLASS64, typ: SHT_ANDROID_RELA,
+			data: aps2(2, 0, 1, addend, 8, relative, 7, 1, 0, 8, relative),
+			want: []rel{{8, relative, 7}, {16, relative, 0}},
+		},
+		{
+			name:  "rel",
+			class: elf.ELFCLASS32, typ: SHT_ANDROID_REL,
+			data: aps2(2, 0x2000, 2, byInfo|byDelta, 4, relative),
+			want: []rel{{0x2004, relative, 0}, {0x2008, relative, 0}},
+		},
+		{
+			name:  "64-bit wraparound",
+			class: elf.ELFCLASS64, typ: SHT_ANDROID_RELA,
+			data: aps2(2, -8, 2, byInfo|byDelta, 8, relative),
+			want: []rel{{0, relative, 0}, {8, relative, 0}},
+		},
+		{
+			name:  "32-bit wraparound",
+			class: elf.ELFCLASS32, typ: SHT_ANDROID_REL,
+			data: aps2(2, 0xfffffff8, 2, byInfo|byDelta, 8, relative),
+			want: []rel{{0, relative, 0}, {8, relative, 0}},
+		},
+		{
+			name:  "32-bit addend wraparound",
+			class: elf.ELFCLASS32, typ: SHT_ANDROID_RELA,
+			data: aps2(2, 0, 2, byInfo|addend, relative, 4, 0x7fffffff, 4, 1),
+			want: []rel{{4, relative, 0x7fffffff}, {8, relative, -0x80000000}},
+		},
+		{
+			name:  "addends in rel",
+			class: elf.ELFCLASS32, typ: SHT_ANDROID_REL,
+			data: aps2(1, 0, 1, addend, 4, relative, 1),
+			err:  ErrBadPacking,
+		},
+		{
+			name:  "no magic",
+			class: elf.ELFCLASS64, typ: SHT_ANDROID_RELA,
+			data: []byte("APS1\x01\x00"),
+			err:  ErrBadPacking,
+		},
+		{
+			name:  "group past count",
+			class: elf.ELFCLASS64, typ: SHT_ANDROID_RELA,
+			data: aps2(1, 0, 2, byInfo, relative, 8, 8),
+			err:  ErrBadPacking,
+		},
+		{
+			name:  "truncated group",
+			class: elf.ELFCLASS64, typ: SHT_ANDROID_RELA,
+			data: aps2(3, 0, 3, byInfo, relative, 8, 8),
+			err:  ErrTruncated,
+		},
+		{
+			name:  "truncated header",
+			class: elf.ELFCLASS64, typ: SHT_ANDROID_RELA,
+			data: aps2(3),
+			err:  ErrTruncated,
+		},
+		{
+			name:  "truncated value",
+			class: elf.ELFCLASS64, typ: SHT_ANDROID_RELA,
+			data: append(aps2(1, 0, 1, byInfo), 0x88),
+			err:  ErrTruncated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rels, err := tableFile(tt.class, tt.typ, tt.data).loadAndroidRelocations(1)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rels) != len(tt.want) {
+				t.Fatalf("%d relocations, want %d", len(rels), len(tt.want))
+			}
+			for i, r := range rels {
+				if got := (rel{r.Off, r.Info, r.Addend}); got != tt.want[i] {
+					t.Errorf("relocation %d = %+v, want %+v", i, got, tt.want[i])
+				}
+				if r.HasAddend != (tt.typ == SHT_ANDROID_RELA) {
+					t.Errorf("relocation %d HasAddend = %v", i, r.HasAddend)
+				}
+			}
+		})
+	}
+}
+
+// testdata/ap.so is ap.c linked with gcc -shared -fPIC, apg.so and apf.so
+// are it with .rela.dyn packed into SHT_ANDROID_RELA, in groups of
+// relocations sharing their info and as a single group sharing nothing.
+func TestAndroidRelocationsMatchUnpacked(t *testing.T) {
+	want := relaDyn(t, "testdata/ap.so", elf.SHT_RELA)
+	for _, name := range []string{"testdata/apg.so", "testdata/apf.so"} {
+		got := relaDyn(t, name, SHT_ANDROID_RELA)
+		if len(got) != len(want) {
+			t.Fatalf("%s: %d relocations, want %d", name, len(got), len(want))
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: relocation %d = %+v, want %+v", name, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func relaDyn(t *testing.T, name string, typ elf.SectionType) []Reloc {
+	t.Helper()
+	elfFs, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer elfFs.Close()
+
+	ndx := elfFs.SectionNdx(".rela.dyn")
+	if ndx == 0 || elfFs.ElfSections.Section[ndx].Type != typ {
+		t.Fatalf("%s: no .rela.dyn of type %v", name, typ)
+	}
+	return elfFs.Rels[ndx]
+}
+
+// The DT_ANDROID_* values are DT_SUNW_* ones on Solaris, they only stand
+// for relocation tables to be rebuilt elsewhere.
+func TestAndroidDynTagsOSABI(t *testing.T) {
+	tests := []struct {
+		file string
+		typ  elf.SectionType
+	}{
+		{"testdata/apg.so", SHT_ANDROID_RELA},
+		{"testdata/arelr.so", SHT_ANDROID_RELR},
+	}
+	for _, tt := range tests {
+		for _, osabi := range []elf.OSABI{elf.ELFOSABI_NONE, elf.ELFOSABI_LINUX, elf.ELFOSABI_SOLARIS} {
+			data, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			/* no section headers, the tables come from PT_DYNAMIC alone */
+			data[elf.EI_OSABI] = byte(osabi)
+			binary.LittleEndian.PutUint64(data[0x28:], 0)
+			binary.LittleEndian.PutUint16(data[0x3c:], 0)
+			binary.LittleEndian.PutUint16(data[0x3e:], 0)
+
+			elfFs, err := NewFile(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := len(elfFs.SectionsByType(tt.typ)) > 0
+			if want := osabi != elf.ELFOSABI_SOLARIS; got != want {
+				t.Errorf("%s with %v: synthesized %v = %v, want %v", tt.file, osabi, tt.typ, got, want)
+			}
+		}
+	}
+}
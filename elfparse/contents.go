@@ -0,0 +1,170 @@
+package elfparse
+
+import (
+	"bytes"
+	"compress/zlib"
+	"debug/elf"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sad0p/go-readelf/elfparse/internal/zstd"
+)
+
+// maxInflation is the most a compressed section is believed to grow by.
+// Deflate can't do better than 1032:1, zstd can but debug info doesn't.
+const maxInflation = 1032
+
+// Chdr is the Elf32_Chdr/Elf64_Chdr heading an SHF_COMPRESSED section.
+type Chdr struct {
+	Type      elf.CompressionType
+	Size      uint64 // size of the uncompressed data
+	Addralign uint64
+}
+
+// LookupSection resolves a section given on the command line, either by
+// index or by name. As with readelf, a number is always taken as an index.
+func (elfFs *ELFFile) LookupSection(spec string) (uint32, error) {
+	if ndx, err := strconv.ParseUint(spec, 10, 32); err == nil {
+		if ndx < uint64(len(elfFs.ElfSections.Section)) {
+			return uint32(ndx), nil
+		}
+	} else if ndx := elfFs.SectionNdx(spec); ndx != 0 {
+		return ndx, nil
+	}
+	return 0, newFormatError(ErrNoSection, elfFs.Hdr.Shoff, "section "+spec, "")
+}
+
+// SectionData returns the bytes of section ndx as stored in the file.
+// SHT_NOBITS sections have none.
+func (elfFs *ELFFile) SectionData(ndx uint32) ([]byte, error) {
+	if ndx >= uint32(len(elfFs.ElfSections.Section)) {
+		return nil, newFormatError(ErrNoSection, elfFs.Hdr.Shoff, "section header table", "index %d", ndx)
+	}
+	sec := elfFs.ElfSections.Section[ndx]
+	if sec.Type == elf.SHT_NOBITS {
+		return nil, nil
+	}
+	return elfFs.readTable("section "+elfFs.ElfSections.SectionName[ndx], sec.Off, sec.Size)
+}
+
+// SectionContents is SectionData with compressed sections inflated, both
+// SHF_COMPRESSED ones and the legacy .zdebug sections.
+func (elfFs *ELFFile) SectionContents(ndx uint32) ([]byte, error) {
+	data, err := elfFs.SectionData(ndx)
+	if err != nil {
+		return nil, err
+	}
+
+	structure := "compressed section " + elfFs.ElfSections.SectionName[ndx]
+	off := elfFs.ElfSections.Section[ndx].Off
+	chdr, hdrSize, err := elfFs.decodeChdr(ndx, data)
+	if err != nil || hdrSize == 0 {
+		return data, err
+	}
+
+	/* checked before inflating anything, ch_size is only the file's word */
+	if compressed := uint64(len(data)) - hdrSize; chdr.Size/maxInflation > compressed {
+		return nil, newFormatError(ErrCompression, off, structure, "0x%x bytes can't inflate to the 0x%x the header says", compressed, chdr.Size)
+	}
+
+	var r io.Reader
+	switch chdr.Type {
+	case elf.COMPRESS_ZLIB:
+		zr, err := zlib.NewReader(bytes.NewReader(data[hdrSize:]))
+		if err != nil {
+			return nil, newFormatError(ErrCompression, off+hdrSize, structure, "%v", err)
+		}
+		defer zr.Close()
+		r = zr
+	case elf.COMPRESS_ZSTD:
+		r = zstd.NewReader(bytes.NewReader(data[hdrSize:]))
+	default:
+		return nil, newFormatError(ErrCompression, off, structure, "unsupported compression type %d", uint32(chdr.Type))
+	}
+
+	/* and even then only as an upper bound */
+	out, err := io.ReadAll(io.LimitReader(r, int64(chdr.Size)+1))
+	if err != nil {
+		return nil, newFormatError(ErrCompression, off+hdrSize, structure, "%v", err)
+	}
+	if uint64(len(out)) != chdr.Size {
+		return nil, newFormatError(ErrCompression, off+hdrSize, structure, "inflated to 0x%x bytes, header says 0x%x", len(out), chdr.Size)
+	}
+	return out, nil
+}
+
+// Compression returns the compression header of section ndx. For .zdebug
+// sections it is made up from their "ZLIB" header. ok is false when the
+// section isn't compressed or its header can't be read.
+func (elfFs *ELFFile) Compression(ndx uint32) (chdr Chdr, ok bool) {
+	if ndx >= uint32(len(elfFs.ElfSections.Section)) || !elfFs.maybeCompressed(ndx) {
+		return Chdr{}, false
+	}
+	sec := elfFs.ElfSections.Section[ndx]
+	data, err := elfFs.readBytes("compression header", sec.Off, min(sec.Size, 24))
+	if err != nil {
+		return Chdr{}, false
+	}
+	chdr, hdrSize, err := elfFs.decodeChdr(ndx, data)
+	return chdr, err == nil && hdrSize != 0
+}
+
+func (elfFs *ELFFile) maybeCompressed(ndx uint32) bool {
+	sec := elfFs.ElfSections.Section[ndx]
+	if sec.Type == elf.SHT_NOBITS {
+		return false
+	}
+	return sec.Flags&elf.SHF_COMPRESSED != 0 || strings.HasPrefix(elfFs.ElfSections.SectionName[ndx], ".zdebug")
+}
+
+// decodeChdr decodes the compression header at the start of data, the
+// contents of section ndx, and returns its size. The size is 0 for
+// sections that aren't compressed.
+func (elfFs *ELFFile) decodeChdr(ndx uint32, data []byte) (Chdr, uint64, error) {
+	if !elfFs.maybeCompressed(ndx) {
+		return Chdr{}, 0, nil
+	}
+	sec := elfFs.ElfSections.Section[ndx]
+	structure := "compressed section " + elfFs.ElfSections.SectionName[ndx]
+
+	/* .zdebug: "ZLIB" and the big endian size of the inflated data */
+	if sec.Flags&elf.SHF_COMPRESSED == 0 {
+		if len(data) < 12 || string(data[:4]) != "ZLIB" {
+			return Chdr{}, 0, nil
+		}
+		return Chdr{Type: elf.COMPRESS_ZLIB, Size: binary.BigEndian.Uint64(data[4:]), Addralign: sec.Addralign}, 12, nil
+	}
+
+	bo := elfFs.FileHdr.Endianness
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		if len(data) < 12 {
+			return Chdr{}, 0, newFormatError(ErrTruncated, sec.Off, structure, "compression header")
+		}
+		return Chdr{
+			Type:      elf.CompressionType(bo.Uint32(data)),
+			Size:      uint64(bo.Uint32(data[4:])),
+			Addralign: uint64(bo.Uint32(data[8:])),
+		}, 12, nil
+	}
+
+	if len(data) < 24 {
+		return Chdr{}, 0, newFormatError(ErrTruncated, sec.Off, structure, "compression header")
+	}
+	return Chdr{
+		Type:      elf.CompressionType(bo.Uint32(data)),
+		Size:      bo.Uint64(data[8:]),
+		Addralign: bo.Uint64(data[16:]),
+	}, 24, nil
+}
+
+// sectionTable reads section ndx for the parsers: like readTable over the
+// section, only compressed sections are inflated first.
+func (elfFs *ELFFile) sectionTable(structure string, ndx uint32) ([]byte, error) {
+	sec := elfFs.ElfSections.Section[ndx]
+	if !elfFs.maybeCompressed(ndx) {
+		return elfFs.readTable(structure, sec.Off, sec.Size)
+	}
+	return elfFs.SectionContents(ndx)
+}
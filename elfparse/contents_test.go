@@ -0,0 +1,120 @@
+package elfparse
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testdata/z.o is z.c compiled with gcc -g -O1 -c, z-zlib.o, z-zlib-gnu.o
+// and z-zstd.o are it through objcopy --compress-debug-sections=zlib,
+// zlib-gnu and zstd.
+func TestSectionContentsInflates(t *testing.T) {
+	orig, err := Open("testdata/z.o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer orig.Close()
+
+	tests := []struct {
+		file   string
+		prefix string // of the compressed debug sections
+		typ    elf.CompressionType
+	}{
+		{"testdata/z-zlib.o", ".debug_", elf.COMPRESS_ZLIB},
+		{"testdata/z-zlib-gnu.o", ".zdebug_", elf.COMPRESS_ZLIB},
+		{"testdata/z-zstd.o", ".debug_", elf.COMPRESS_ZSTD},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			elfFs, err := Open(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer elfFs.Close()
+
+			compressed := 0
+			for ndx, name := range elfFs.ElfSections.SectionName {
+				if !strings.HasPrefix(name, tt.prefix) {
+					continue
+				}
+				/* objcopy leaves the sections compression wouldn't shrink alone */
+				chdr, ok := elfFs.Compression(uint32(ndx))
+				if ok {
+					compressed++
+					if chdr.Type != tt.typ {
+						t.Errorf("%s compressed with %v, want %v", name, chdr.Type, tt.typ)
+					}
+				}
+
+				origNdx := orig.SectionNdx(".debug_" + strings.TrimPrefix(name, tt.prefix))
+				want, err := orig.SectionData(origNdx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := elfFs.SectionContents(uint32(ndx))
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("%s inflated to %d bytes that aren't the %d of the original", name, len(got), len(want))
+				}
+			}
+			if compressed == 0 {
+				t.Errorf("no compressed %s* sections", tt.prefix)
+			}
+		})
+	}
+}
+
+// The size in the compression header is checked against what the
+// compressed bytes can hold before anything is inflated.
+func TestSectionContentsSize(t *testing.T) {
+	huge := func(uint64) uint64 { return 1 << 62 }
+	tests := []struct {
+		name    string
+		file    string
+		section string
+		off     uint64 // of the size in the section
+		order   binary.ByteOrder
+		size    func(uint64) uint64
+		header  bool // refused before inflating, the error is at the header
+	}{
+		{"huge ch_size", "testdata/z-zlib.o", ".debug_info", 8, binary.LittleEndian, huge, true},
+		{"short ch_size", "testdata/z-zstd.o", ".debug_info", 8, binary.LittleEndian, func(n uint64) uint64 { return n - 1 }, false},
+		{"long ch_size", "testdata/z-zlib.o", ".debug_info", 8, binary.LittleEndian, func(n uint64) uint64 { return n + 1 }, false},
+		{"huge .zdebug size", "testdata/z-zlib-gnu.o", ".zdebug_info", 4, binary.BigEndian, huge, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := elf.NewFile(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			off := f.Section(tt.section).Offset
+			size := data[off+tt.off:]
+			tt.order.PutUint64(size, tt.size(tt.order.Uint64(size)))
+
+			elfFs, err := NewFile(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = elfFs.SectionContents(elfFs.SectionNdx(tt.section))
+			var fe *FormatError
+			if !errors.Is(err, ErrCompression) || !errors.As(err, &fe) {
+				t.Fatalf("SectionContents: %v, want %v", err, ErrCompression)
+			}
+			if got := fe.Off == int64(off); got != tt.header {
+				t.Errorf("error at offset 0x%x, section at 0x%x", fe.Off, off)
+			}
+		})
+	}
+}
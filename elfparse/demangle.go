@@ -0,0 +1,64 @@
+package elfparse
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ianlancetaylor/demangle"
+)
+
+// Demangle returns the source-level name of an Itanium C++ ABI or Rust
+// (legacy or v0) mangled symbol, or name itself if it isn't one. A
+// version suffix, as in "_ZN3foo3barEv@@VERS_1", is kept as it is.
+func Demangle(name string) string {
+	base, ver, versioned := strings.Cut(name, "@")
+	if s, err := demangle.ToString(rustLegacyUnderscores(base)); err == nil {
+		base = s
+	}
+	if versioned {
+		return base + "@" + ver
+	}
+	return base
+}
+
+// rustLegacyUnderscores works around the demangler taking an underscore
+// after the length of a legacy Rust identifier for a separator, which
+// that scheme doesn't have: _print in _ZN3std2io5stdio6_print17h...E
+// would come out as print. Doubling the underscore, and counting it,
+// keeps the identifier whole. C++ names are left alone.
+func rustLegacyUnderscores(name string) string {
+	/* the legacy Rust shape the demangler looks for, _ZN...17h<16 hex>E[.suffix] */
+	mangled := name
+	if pos := strings.LastIndex(mangled, "E."); pos > 0 {
+		mangled = mangled[:pos+1]
+	}
+	if !strings.HasPrefix(mangled, "_ZN") || !strings.HasSuffix(mangled, "E") ||
+		len(mangled) <= 23 || mangled[len(mangled)-20:len(mangled)-17] != "17h" {
+		return name
+	}
+
+	var sb strings.Builder
+	sb.WriteString("_ZN")
+	rest := name[3:]
+	for len(rest) > 0 && rest[0] >= '1' && rest[0] <= '9' {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil || n > len(rest)-i {
+			return name
+		}
+		id := rest[i : i+n]
+		if id[0] == '_' {
+			sb.WriteString(strconv.Itoa(n + 1))
+			sb.WriteByte('_')
+		} else {
+			sb.WriteString(rest[:i])
+		}
+		sb.WriteString(id)
+		rest = rest[i+n:]
+	}
+	sb.WriteString(rest)
+	return sb.String()
+}
@@ -0,0 +1,26 @@
+package elfparse
+
+import "testing"
+
+func TestDemangle(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"_Z1fN2ns3BoxIlEEPKcz", "f(ns::Box<long>, char const*, ...)"},
+		{"_ZN3foo4_barEv", "foo::_bar()"},
+		{"_ZN3foo3barEv@@VERS_1", "foo::bar()@@VERS_1"},
+		{"_ZN4core3fmt9Formatter3pad17h1234567890abcdefE", "core::fmt::Formatter::pad"},
+		{"_ZN3std2io5stdio6_print17h0123456789abcdefE", "std::io::stdio::_print"},
+		{"_ZN3std2io5stdio6_print17h0123456789abcdefE.llvm.123", "std::io::stdio::_print"},
+		{"_ZN3foo5_$LT$17h0123456789abcdefE", "foo::<"},
+		{"_RNvCs1234_7mycrate3foo", "mycrate::foo"},
+		{"_ZN3foo99_short17h0123456789abcdefE", "_ZN3foo99_short17h0123456789abcdefE"},
+		{"main", "main"},
+		{"memcpy@GLIBC_2.14", "memcpy@GLIBC_2.14"},
+	}
+	for _, tt := range tests {
+		if got := Demangle(tt.name); got != tt.want {
+			t.Errorf("Demangle(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
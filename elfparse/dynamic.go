@@ -0,0 +1,86 @@
+package elfparse
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+)
+
+// DynEntry is one tag/value pair of the dynamic section.
+type DynEntry struct {
+	Tag elf.DynTag
+	Val uint64
+}
+
+// getDynamic reads the dynamic section through PT_DYNAMIC, which is what
+// the loader uses, falling back to the .dynamic section header.
+func (elfFs *ELFFile) getDynamic() error {
+	elfFs.Dynamic = nil
+
+	var off, size uint64
+	found := false
+	for _, prog := range elfFs.ProgHeaders {
+		if prog.Type == elf.PT_DYNAMIC {
+			off, size, found = prog.Off, prog.Filesz, true
+			break
+		}
+	}
+	if !found {
+		ndx := elfFs.SectionNdx(".dynamic")
+		if ndx == 0 || elfFs.ElfSections.Section[ndx].Type != elf.SHT_DYNAMIC {
+			return nil
+		}
+		off, size = elfFs.ElfSections.Section[ndx].Off, elfFs.ElfSections.Section[ndx].Size
+	}
+
+	data, err := elfFs.readTable("dynamic section", off, size)
+	if err != nil {
+		return err
+	}
+
+	entSize := uint64(16)
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		entSize = 8
+	}
+
+	r := bytes.NewReader(data)
+	for i := uint64(0); i < uint64(len(data))/entSize; i++ {
+		var entry DynEntry
+		if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+			var dyn elf.Dyn32
+			binary.Read(r, elfFs.FileHdr.Endianness, &dyn)
+			entry = DynEntry{Tag: elf.DynTag(dyn.Tag), Val: uint64(dyn.Val)}
+		} else {
+			var dyn elf.Dyn64
+			binary.Read(r, elfFs.FileHdr.Endianness, &dyn)
+			entry = DynEntry{Tag: elf.DynTag(dyn.Tag), Val: dyn.Val}
+		}
+
+		elfFs.Dynamic = append(elfFs.Dynamic, entry)
+		if entry.Tag == elf.DT_NULL {
+			break
+		}
+	}
+
+	/* string valued tags go through the table the loader would use */
+	if strtab, ok := elfFs.DynValue(elf.DT_STRTAB); ok {
+		strsz, _ := elfFs.DynValue(elf.DT_STRSZ)
+		if off, ok := elfFs.VaddrToOff(strtab); ok {
+			elfFs.dynstrOff = off
+			elfFs.dynstr, err = elfFs.readTable("dynamic string table", off, strsz)
+			return err
+		}
+		return newFormatError(ErrOutOfRange, off, "dynamic section", "DT_STRTAB 0x%x isn't mapped by any PT_LOAD", strtab)
+	}
+	return nil
+}
+
+// DynValue returns the value of the first dynamic entry tagged tag.
+func (elfFs *ELFFile) DynValue(tag elf.DynTag) (uint64, bool) {
+	for _, entry := range elfFs.Dynamic {
+		if entry.Tag == tag {
+			return entry.Val, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,256 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"fmt"
+	"strings"
+)
+
+// Dynamic tags debug/elf doesn't define.
+const (
+	DT_SYMTAB_SHNDX elf.DynTag = 34
+	DT_RELRSZ       elf.DynTag = 35
+	DT_RELR         elf.DynTag = 36
+	DT_RELRENT      elf.DynTag = 37
+
+	DT_GNU_FLAGS_1 elf.DynTag = 0x6ffffdf4
+
+	DT_ANDROID_REL       elf.DynTag = 0x6000000f
+	DT_ANDROID_RELSZ     elf.DynTag = 0x60000010
+	DT_ANDROID_RELA      elf.DynTag = 0x60000011
+	DT_ANDROID_RELASZ    elf.DynTag = 0x60000012
+	DT_ANDROID_RELR      elf.DynTag = 0x6fffe000
+	DT_ANDROID_RELRSZ    elf.DynTag = 0x6fffe001
+	DT_ANDROID_RELRENT   elf.DynTag = 0x6fffe003
+	DT_ANDROID_RELRCOUNT elf.DynTag = 0x6fffe005
+)
+
+/* the OS range is shared, which names apply depends on EI_OSABI */
+var solarisDynTags = map[elf.DynTag]string{
+	0x6000000d: "DT_SUNW_AUXILIARY",
+	0x6000000e: "DT_SUNW_RTLDINF",
+	0x6000000f: "DT_SUNW_FILTER",
+	0x60000010: "DT_SUNW_CAP",
+	0x60000011: "DT_SUNW_SYMTAB",
+	0x60000012: "DT_SUNW_SYMSZ",
+	0x60000013: "DT_SUNW_SORTENT",
+	0x60000014: "DT_SUNW_SYMSORT",
+	0x60000015: "DT_SUNW_SYMSORTSZ",
+	0x60000016: "DT_SUNW_TLSSORT",
+	0x60000017: "DT_SUNW_TLSSORTSZ",
+	0x60000018: "DT_SUNW_CAPINFO",
+	0x60000019: "DT_SUNW_STRPAD",
+	0x6000001a: "DT_SUNW_CAPCHAIN",
+	0x6000001b: "DT_SUNW_LDMACH",
+	0x6000001d: "DT_SUNW_CAPCHAINENT",
+	0x6000001f: "DT_SUNW_CAPCHAINSZ",
+	0x60000021: "DT_SUNW_PARENT",
+	0x60000023: "DT_SUNW_ASLR",
+	0x60000025: "DT_SUNW_RELAX",
+	0x60000029: "DT_SUNW_NXHEAP",
+	0x6000002b: "DT_SUNW_NXSTACK",
+}
+
+var androidDynTags = map[elf.DynTag]string{
+	DT_ANDROID_REL:       "DT_ANDROID_REL",
+	DT_ANDROID_RELSZ:     "DT_ANDROID_RELSZ",
+	DT_ANDROID_RELA:      "DT_ANDROID_RELA",
+	DT_ANDROID_RELASZ:    "DT_ANDROID_RELASZ",
+	DT_ANDROID_RELR:      "DT_ANDROID_RELR",
+	DT_ANDROID_RELRSZ:    "DT_ANDROID_RELRSZ",
+	DT_ANDROID_RELRENT:   "DT_ANDROID_RELRENT",
+	DT_ANDROID_RELRCOUNT: "DT_ANDROID_RELRCOUNT",
+}
+
+var gnuDynTags = map[elf.DynTag]string{
+	elf.DT_PREINIT_ARRAY: "DT_PREINIT_ARRAY", // debug/elf calls it DT_ENCODING
+	DT_SYMTAB_SHNDX:      "DT_SYMTAB_SHNDX",
+	DT_RELRSZ:            "DT_RELRSZ",
+	DT_RELR:              "DT_RELR",
+	DT_RELRENT:           "DT_RELRENT",
+	DT_GNU_FLAGS_1:       "DT_GNU_FLAGS_1",
+}
+
+/* the processor range means something different on every machine */
+var procDynTags = map[elf.Machine]map[elf.DynTag]string{
+	elf.EM_X86_64: {
+		0x70000000: "DT_X86_64_PLT",
+		0x70000001: "DT_X86_64_PLTSZ",
+		0x70000003: "DT_X86_64_PLTENT",
+	},
+	elf.EM_AARCH64: {
+		0x70000001: "DT_AARCH64_BTI_PLT",
+		0x70000003: "DT_AARCH64_PAC_PLT",
+		0x70000005: "DT_AARCH64_VARIANT_PCS",
+		0x70000009: "DT_AARCH64_MEMTAG_MODE",
+		0x7000000b: "DT_AARCH64_MEMTAG_HEAP",
+		0x7000000c: "DT_AARCH64_MEMTAG_STACK",
+		0x7000000d: "DT_AARCH64_MEMTAG_GLOBALS",
+		0x7000000f: "DT_AARCH64_MEMTAG_GLOBALSSZ",
+	},
+	elf.EM_PPC: {
+		0x70000000: "DT_PPC_GOT",
+		0x70000001: "DT_PPC_OPT",
+	},
+	elf.EM_PPC64: {
+		0x70000000: "DT_PPC64_GLINK",
+		0x70000001: "DT_PPC64_OPD",
+		0x70000002: "DT_PPC64_OPDSZ",
+		0x70000003: "DT_PPC64_OPT",
+	},
+	elf.EM_SPARCV9: {
+		0x70000001: "DT_SPARC_REGISTER",
+	},
+	elf.EM_SPARC: {
+		0x70000001: "DT_SPARC_REGISTER",
+	},
+	elf.EM_RISCV: {
+		0x70000001: "DT_RISCV_VARIANT_CC",
+	},
+}
+
+// DynTagName names tag for this binary. Tags in the OS specific range are
+// looked up by EI_OSABI (Solaris, otherwise GNU and Android) and tags in
+// the processor range by e_machine, since the same values are reused.
+func (elfFs *ELFFile) DynTagName(tag elf.DynTag) string {
+	osabi := elf.OSABI(elfFs.Ident[elf.EI_OSABI])
+
+	switch {
+	case tag >= elf.DT_LOPROC && tag <= elf.DT_HIPROC:
+		if elfFs.FileHdr.Machine == elf.EM_MIPS {
+			if name := tag.String(); strings.HasPrefix(name, "DT_MIPS_") && !strings.Contains(name, "+") {
+				return name
+			}
+		}
+		if name, ok := procDynTags[elfFs.FileHdr.Machine][tag]; ok {
+			return name
+		}
+		switch tag {
+		case elf.DT_AUXILIARY, elf.DT_USED, elf.DT_FILTER:
+			return tag.String()
+		}
+		return fmt.Sprintf("DT_LOPROC+0x%x", uint64(tag-elf.DT_LOPROC))
+
+	case tag >= elf.DT_LOOS && tag <= elf.DT_HIOS:
+		if osabi == elf.ELFOSABI_SOLARIS {
+			if name, ok := solarisDynTags[tag]; ok {
+				return name
+			}
+		} else if name, ok := androidDynTags[tag]; ok {
+			return name
+		}
+		return fmt.Sprintf("DT_LOOS+0x%x", uint64(tag-elf.DT_LOOS))
+	}
+
+	if name, ok := gnuDynTags[tag]; ok {
+		return name
+	}
+	if name := tag.String(); strings.HasPrefix(name, "DT_") && !strings.Contains(name, "+") {
+		return name
+	}
+	return fmt.Sprintf("0x%x", uint64(tag))
+}
+
+// DynKind says how the value of a dynamic entry is to be read.
+type DynKind int
+
+const (
+	DynKindValue   DynKind = iota // plain number
+	DynKindAddress                // virtual address
+	DynKindSize                   // size in bytes
+	DynKindString                 // offset into the dynamic string table
+	DynKindFlags                  // DT_FLAGS/DT_FLAGS_1 style bitmask
+	DynKindTag                    // another tag, e.g. DT_PLTREL
+)
+
+// DynTagKind classifies the value of entries tagged tag.
+func (elfFs *ELFFile) DynTagKind(tag elf.DynTag) DynKind {
+	osabi := elf.OSABI(elfFs.Ident[elf.EI_OSABI])
+
+	switch tag {
+	case elf.DT_NEEDED, elf.DT_SONAME, elf.DT_RPATH, elf.DT_RUNPATH, elf.DT_CONFIG,
+		elf.DT_DEPAUDIT, elf.DT_AUDIT, elf.DT_AUXILIARY, elf.DT_FILTER, elf.DT_USED:
+		return DynKindString
+	case elf.DT_FLAGS, elf.DT_FLAGS_1, DT_GNU_FLAGS_1, elf.DT_POSFLAG_1, elf.DT_FEATURE:
+		return DynKindFlags
+	case elf.DT_PLTREL:
+		return DynKindTag
+	case elf.DT_PLTRELSZ, elf.DT_RELASZ, elf.DT_RELAENT, elf.DT_STRSZ, elf.DT_SYMENT,
+		elf.DT_RELSZ, elf.DT_RELENT, elf.DT_INIT_ARRAYSZ, elf.DT_FINI_ARRAYSZ,
+		elf.DT_PREINIT_ARRAYSZ, DT_RELRSZ, DT_RELRENT, elf.DT_GNU_CONFLICTSZ,
+		elf.DT_GNU_LIBLISTSZ, elf.DT_PLTPADSZ, elf.DT_MOVEENT, elf.DT_MOVESZ,
+		elf.DT_SYMINSZ, elf.DT_SYMINENT:
+		return DynKindSize
+	case elf.DT_PLTGOT, elf.DT_HASH, elf.DT_STRTAB, elf.DT_SYMTAB, elf.DT_RELA,
+		elf.DT_INIT, elf.DT_FINI, elf.DT_REL, elf.DT_DEBUG, elf.DT_JMPREL,
+		elf.DT_INIT_ARRAY, elf.DT_FINI_ARRAY, elf.DT_PREINIT_ARRAY, DT_SYMTAB_SHNDX,
+		DT_RELR, elf.DT_GNU_HASH, elf.DT_TLSDESC_PLT, elf.DT_TLSDESC_GOT,
+		elf.DT_GNU_CONFLICT, elf.DT_GNU_LIBLIST, elf.DT_PLTPAD, elf.DT_MOVETAB,
+		elf.DT_SYMINFO, elf.DT_VERSYM, elf.DT_VERDEF, elf.DT_VERNEED:
+		return DynKindAddress
+	}
+
+	if tag >= elf.DT_LOOS && tag <= elf.DT_HIOS {
+		switch {
+		case osabi == elf.ELFOSABI_SOLARIS && (tag == 0x6000000d || tag == 0x6000000f):
+			return DynKindString
+		case osabi != elf.ELFOSABI_SOLARIS && (tag == DT_ANDROID_REL || tag == DT_ANDROID_RELA || tag == DT_ANDROID_RELR):
+			return DynKindAddress
+		case osabi != elf.ELFOSABI_SOLARIS && (tag == DT_ANDROID_RELSZ || tag == DT_ANDROID_RELASZ ||
+			tag == DT_ANDROID_RELRSZ || tag == DT_ANDROID_RELRENT):
+			return DynKindSize
+		}
+	}
+	return DynKindValue
+}
+
+var gnuFlags1Names = []string{"DF_GNU_1_UNIQUE"}
+
+var posFlag1Names = []string{"DF_P1_LAZYLOAD", "DF_P1_GROUPPERM"}
+
+var feature1Names = []string{"DTF_1_PARINIT", "DTF_1_CONFEXP"}
+
+// DynFlagNames splits the bitmask of a DT_FLAGS, DT_FLAGS_1, DT_GNU_FLAGS_1,
+// DT_POSFLAG_1 or DT_FEATURE_1 entry into flag names. Unknown bits are
+// kept as a trailing hex value.
+func DynFlagNames(tag elf.DynTag, val uint64) []string {
+	var s string
+	switch tag {
+	case elf.DT_FLAGS:
+		s = elf.DynFlag(val).String()
+	case elf.DT_FLAGS_1:
+		s = elf.DynFlag1(uint32(val)).String()
+	default:
+		var names []string
+		switch tag {
+		case DT_GNU_FLAGS_1:
+			names = gnuFlags1Names
+		case elf.DT_POSFLAG_1:
+			names = posFlag1Names
+		case elf.DT_FEATURE:
+			names = feature1Names
+		}
+		var flags []string
+		for bit, name := range names {
+			if val&(1<<bit) != 0 {
+				flags = append(flags, name)
+				val &^= 1 << bit
+			}
+		}
+		if val != 0 || len(flags) == 0 {
+			flags = append(flags, fmt.Sprintf("0x%x", val))
+		}
+		return flags
+	}
+	return strings.Split(s, "+")
+}
+
+// DynString returns the string a DynKindString entry refers to, read
+// from the table DT_STRTAB points at.
+func (elfFs *ELFFile) DynString(entry DynEntry) (string, error) {
+	if entry.Val >= uint64(len(elfFs.dynstr)) {
+		return "", newFormatError(ErrBadStringIndex, elfFs.dynstrOff, "dynamic string table",
+			"%s at 0x%x, table is 0x%x bytes", elfFs.DynTagName(entry.Tag), entry.Val, len(elfFs.dynstr))
+	}
+	return getSectionName(uint32(entry.Val), elfFs.dynstr), nil
+}
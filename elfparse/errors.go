@@ -0,0 +1,58 @@
+package elfparse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Error kinds reported by the parser. Parse failures are returned as a
+// *FormatError wrapping one of these, so callers can branch on the kind
+// with errors.Is and recover the location with errors.As.
+var (
+	ErrNotELF         = errors.New("not an elf binary")
+	ErrUnknownClass   = errors.New("unknown elf class")
+	ErrUnknownData    = errors.New("unknown endianness")
+	ErrTruncated      = errors.New("data truncated")
+	ErrOutOfRange     = errors.New("offset out of range")
+	ErrBadStringIndex = errors.New("bad string index")
+	ErrBadLink        = errors.New("bad section link")
+	ErrBadEntrySize   = errors.New("bad entry size")
+	ErrNoSection      = errors.New("no such section")
+	ErrCompression    = errors.New("bad compressed section")
+	ErrBadPacking     = errors.New("bad packed relocations")
+)
+
+// FormatError describes a structure of the binary that could not be parsed.
+type FormatError struct {
+	Off       int64  // file offset of the structure that failed
+	Structure string // what was being parsed, e.g. "section header table"
+	Err       error  // one of the Err* kinds above, or the underlying I/O error
+	Detail    string // optional extra context
+}
+
+func (e *FormatError) Error() string {
+	msg := fmt.Sprintf("%s at offset 0x%x: %v", e.Structure, e.Off, e.Err)
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	return msg
+}
+
+func (e *FormatError) Unwrap() error { return e.Err }
+
+func newFormatError(kind error, off uint64, structure string, detail string, args ...interface{}) *FormatError {
+	if len(args) > 0 {
+		detail = fmt.Sprintf(detail, args...)
+	}
+	return &FormatError{Off: int64(off), Structure: structure, Err: kind, Detail: detail}
+}
+
+// ioError turns a short read into ErrTruncated and keeps anything else
+// (a failing disk, a closed file) as the detail.
+func ioError(err error, off uint64, structure string) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return newFormatError(ErrTruncated, off, structure, "")
+	}
+	return &FormatError{Off: int64(off), Structure: structure, Err: err}
+}
@@ -0,0 +1,310 @@
+// Package elfparse decodes ELF binaries into a model of their header,
+// sections, program headers, symbols and relocations. It relies on
+// debug/elf only for typing and structure information; the parsing itself
+// is done independently.
+package elfparse
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+)
+
+// Options control how a binary is parsed. The zero value is strict
+// parsing, where the first malformed structure aborts with a FormatError.
+type Options struct {
+	// Resilient bounds every table against the file size and tolerates
+	// nonstandard entry sizes. Anomalies are recorded in ELFFile.Warnings
+	// and as much of every table as can be trusted is kept, much like the
+	// kernel loader still runs a binary whose section metadata is garbage.
+	Resilient bool
+}
+
+// Open opens the named file and parses it with NewFile.
+func Open(name string) (*ELFFile, error) {
+	return OpenOptions(name, Options{})
+}
+
+// OpenOptions is Open with explicit parsing options.
+func OpenOptions(name string, opts Options) (*ELFFile, error) {
+	fh, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := fh.Stat()
+	if err != nil {
+		fh.Close()
+		return nil, err
+	}
+
+	elfFs, err := newFile(fh, fi.Size(), opts)
+	if err != nil {
+		fh.Close()
+		return nil, err
+	}
+	elfFs.closer = fh
+	return elfFs, nil
+}
+
+// NewFile parses the ELF binary readable through r. The header, sections,
+// program headers, symbols and relocations are all decoded up front.
+func NewFile(r io.ReaderAt) (*ELFFile, error) {
+	return NewFileOptions(r, Options{})
+}
+
+// NewFileOptions is NewFile with explicit parsing options.
+func NewFileOptions(r io.ReaderAt, opts Options) (*ELFFile, error) {
+	var size int64
+	switch v := r.(type) {
+	case interface{ Size() int64 }:
+		size = v.Size()
+	case *os.File:
+		if fi, err := v.Stat(); err == nil {
+			size = fi.Size()
+		}
+	}
+	return newFile(r, size, opts)
+}
+
+// Close closes the underlying file if the ELFFile was created with Open.
+func (elfFs *ELFFile) Close() error {
+	if elfFs.closer == nil {
+		return nil
+	}
+	err := elfFs.closer.Close()
+	elfFs.closer = nil
+	return err
+}
+
+func newFile(r io.ReaderAt, size int64, opts Options) (*ELFFile, error) {
+	elfFs := &ELFFile{Fh: r, Size: size, resilient: opts.Resilient}
+
+	/* judge the magic on whatever is there, a 3 byte text file is no truncated ELF */
+	n, err := r.ReadAt(elfFs.Ident[:], 0)
+	if err != nil && err != io.EOF {
+		return nil, ioError(err, 0, "elf ident")
+	}
+	if !isElf(elfFs.Ident[:n]) {
+		return nil, newFormatError(ErrNotELF, 0, "elf ident", "magic % x", elfFs.Ident[:min(n, len(elf.ELFMAG))])
+	}
+	if n < elf.EI_NIDENT {
+		return nil, newFormatError(ErrTruncated, 0, "elf ident", "%d of %d bytes", n, elf.EI_NIDENT)
+	}
+
+	if err := elfFs.setArch(); err != nil {
+		return nil, err
+	}
+	if err := elfFs.mapHeader(); err != nil {
+		return nil, err
+	}
+
+	/* nothing below is needed to run the binary, so a resilient parse keeps going */
+	steps := []func() error{
+		elfFs.getExtendedNumbering,
+		elfFs.getProgHeaders,
+		elfFs.getSections,
+		elfFs.getDynamic,
+		elfFs.synthesizeSections,
+		elfFs.getSymbols,
+		elfFs.getVersions,
+		elfFs.getHashTables,
+		elfFs.getRelocations,
+		elfFs.getNotes,
+	}
+	for _, step := range steps {
+		if err := elfFs.anomaly(step()); err != nil {
+			return nil, err
+		}
+	}
+	return elfFs, nil
+}
+
+func (elfFs *ELFFile) setArch() error {
+	switch elf.Class(elfFs.Ident[elf.EI_CLASS]) {
+	case elf.ELFCLASS64:
+		elfFs.FileHdr.Arch = elf.ELFCLASS64
+	case elf.ELFCLASS32:
+		elfFs.FileHdr.Arch = elf.ELFCLASS32
+	default:
+		return newFormatError(ErrUnknownClass, elf.EI_CLASS, "elf ident", "class %d", elfFs.Ident[elf.EI_CLASS])
+	}
+	return nil
+}
+
+func (elfFs *ELFFile) mapHeader() error {
+
+	switch elf.Data(elfFs.Ident[elf.EI_DATA]) {
+	case elf.ELFDATA2LSB:
+		elfFs.FileHdr.Endianness = binary.LittleEndian
+	case elf.ELFDATA2MSB:
+		elfFs.FileHdr.Endianness = binary.BigEndian
+	default:
+		return newFormatError(ErrUnknownData, elf.EI_DATA, "elf ident", "data encoding %d", elfFs.Ident[elf.EI_DATA])
+	}
+
+	h := &elfFs.Hdr
+	h.Class = elfFs.FileHdr.Arch
+
+	switch elfFs.FileHdr.Arch {
+	case elf.ELFCLASS32:
+		var hdr elf.Header32
+		buf, err := elfFs.readBytes("elf header", 0, uint64(binary.Size(hdr)))
+		if err != nil {
+			return err
+		}
+		binary.Read(bytes.NewReader(buf), elfFs.FileHdr.Endianness, &hdr)
+		h.Ident = hdr.Ident
+		h.Type = elf.Type(hdr.Type)
+		h.Machine = elf.Machine(hdr.Machine)
+		h.Version = elf.Version(hdr.Version)
+		h.Entry = uint64(hdr.Entry)
+		h.Phoff = uint64(hdr.Phoff)
+		h.Shoff = uint64(hdr.Shoff)
+		h.Flags = hdr.Flags
+		h.Ehsize = hdr.Ehsize
+		h.Phentsize = hdr.Phentsize
+		h.Phnum = hdr.Phnum
+		h.Shentsize = hdr.Shentsize
+		h.Shnum = hdr.Shnum
+		h.Shstrndx = hdr.Shstrndx
+
+	case elf.ELFCLASS64:
+		var hdr elf.Header64
+		buf, err := elfFs.readBytes("elf header", 0, uint64(binary.Size(hdr)))
+		if err != nil {
+			return err
+		}
+		binary.Read(bytes.NewReader(buf), elfFs.FileHdr.Endianness, &hdr)
+		h.Ident = hdr.Ident
+		h.Type = elf.Type(hdr.Type)
+		h.Machine = elf.Machine(hdr.Machine)
+		h.Version = elf.Version(hdr.Version)
+		h.Entry = hdr.Entry
+		h.Phoff = hdr.Phoff
+		h.Shoff = hdr.Shoff
+		h.Flags = hdr.Flags
+		h.Ehsize = hdr.Ehsize
+		h.Phentsize = hdr.Phentsize
+		h.Phnum = hdr.Phnum
+		h.Shentsize = hdr.Shentsize
+		h.Shnum = hdr.Shnum
+		h.Shstrndx = hdr.Shstrndx
+	}
+
+	elfFs.FileHdr.Machine = h.Machine
+	return nil
+}
+
+// PN_XNUM is the e_phnum of a file with more program headers than fit,
+// their number is in the sh_info of section header 0.
+const PN_XNUM = 0xffff
+
+// getExtendedNumbering resolves the header's counts, reading them from
+// section header 0 where the header fields are escapes: e_shnum 0 takes
+// sh_size, e_shstrndx SHN_XINDEX sh_link and e_phnum PN_XNUM sh_info.
+func (elfFs *ELFFile) getExtendedNumbering() error {
+	h := &elfFs.Hdr
+	h.SegmentCount, h.SectionCount, h.ShstrIndex = uint32(h.Phnum), uint32(h.Shnum), uint32(h.Shstrndx)
+	if h.Shoff == 0 || h.Shnum != 0 && h.Shstrndx != uint16(elf.SHN_XINDEX) && h.Phnum != PN_XNUM {
+		return nil
+	}
+
+	buf, err := elfFs.readBytes("section header 0", h.Shoff, elfFs.shdrSize())
+	if err != nil {
+		return err
+	}
+	var sec [1]Section
+	elfFs.decodeSections(bytes.NewReader(buf), sec[:])
+
+	if h.Shnum == 0 {
+		if sec[0].Size > math.MaxUint32 {
+			return newFormatError(ErrOutOfRange, h.Shoff, "section header 0", "sh_size %d is no section count", sec[0].Size)
+		}
+		h.SectionCount = uint32(sec[0].Size)
+	}
+	if h.Shstrndx == uint16(elf.SHN_XINDEX) {
+		h.ShstrIndex = sec[0].Link
+	}
+	if h.Phnum == PN_XNUM {
+		h.SegmentCount = sec[0].Info
+	}
+	return nil
+}
+
+func isElf(ident []byte) bool {
+	return bytes.HasPrefix(ident, []byte(elf.ELFMAG))
+}
+
+// readBytes reads size bytes at off from the underlying file. structure
+// names what is being read for error reporting.
+func (elfFs *ELFFile) readBytes(structure string, off, size uint64) ([]byte, error) {
+	if elfFs.Size > 0 && off > uint64(elfFs.Size) {
+		return nil, newFormatError(ErrOutOfRange, off, structure, "file is 0x%x bytes", elfFs.Size)
+	}
+	if elfFs.Size > 0 && size > uint64(elfFs.Size)-off {
+		return nil, newFormatError(ErrTruncated, off, structure, "0x%x bytes needed, 0x%x left in file", size, uint64(elfFs.Size)-off)
+	}
+
+	sr := io.NewSectionReader(elfFs.Fh, int64(off), int64(size))
+	if elfFs.Size <= 0 {
+		/* size unknown, let the buffer grow with what is actually there */
+		buf, err := io.ReadAll(sr)
+		if err == nil && uint64(len(buf)) < size {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, ioError(err, off, structure)
+		}
+		return buf, nil
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(sr, buf); err != nil {
+		return nil, ioError(err, off, structure)
+	}
+	return buf, nil
+}
+
+// readTable is readBytes for tables whose location and size come from the
+// binary itself. In resilient mode a table running past the end of the file
+// is cut down to the bytes actually present.
+func (elfFs *ELFFile) readTable(structure string, off, size uint64) ([]byte, error) {
+	data, err := elfFs.readBytes(structure, off, size)
+	if err == nil || !elfFs.resilient {
+		return data, err
+	}
+
+	elfFs.anomaly(err)
+	if elfFs.Size <= 0 || off >= uint64(elfFs.Size) {
+		return nil, nil
+	}
+	return elfFs.readBytes(structure, off, uint64(elfFs.Size)-off)
+}
+
+// anomaly records err as a warning and swallows it in resilient mode,
+// otherwise err is returned unchanged.
+func (elfFs *ELFFile) anomaly(err error) error {
+	if err == nil || !elfFs.resilient {
+		return err
+	}
+	elfFs.Warnings = append(elfFs.Warnings, err)
+	return nil
+}
+
+// entrySize checks the entry size a header claims for a table against the
+// size of the structure for this class. Larger entries are fine, the extra
+// bytes are skipped; smaller ones can't be decoded.
+func (elfFs *ELFFile) entrySize(structure string, off uint64, entsize uint64, want uint64) (uint64, error) {
+	switch {
+	case entsize == want:
+		return want, nil
+	case entsize > want:
+		return entsize, elfFs.anomaly(newFormatError(ErrBadEntrySize, off, structure, "entry size %d, expected %d", entsize, want))
+	default:
+		return 0, newFormatError(ErrBadEntrySize, off, structure, "entry size %d, expected %d", entsize, want)
+	}
+}
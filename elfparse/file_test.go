@@ -0,0 +1,112 @@
+package elfparse
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestNewFileIdent(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrNotELF},
+		{"short text", []byte("abc"), ErrNotELF},
+		{"magic prefix", []byte("\x7fEL"), ErrNotELF},
+		{"script", []byte("#!/bin/sh\necho hello\n"), ErrNotELF},
+		{"short ident", []byte("\x7fELF\x02\x01"), ErrTruncated},
+		{"bad class", append([]byte("\x7fELF\x07\x01\x01"), make([]byte, 64)...), ErrUnknownClass},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFile(bytes.NewReader(tt.data))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("NewFile: %v, want %v", err, tt.want)
+			}
+			var fe *FormatError
+			if !errors.As(err, &fe) {
+				t.Fatalf("NewFile: %T is no *FormatError", err)
+			}
+		})
+	}
+}
+
+// xnumFile is a relocatable file with more sections than e_shnum and
+// e_shstrndx hold and its one program header counted in section 0 too.
+// Its only symbol is defined in section target through SHT_SYMTAB_SHNDX.
+func xnumFile(shnum, target uint32) []byte {
+	le := binary.LittleEndian
+	shstrtab := "\x00.s\x00.symtab\x00.strtab\x00.symtab_shndx\x00.shstrtab\x00"
+	strtab := "\x00x\x00"
+	symtabOff := uint64(64 + 56 + len(shstrtab) + len(strtab))
+	shndxOff := symtabOff + 2*24
+	shoff := shndxOff + 2*4
+
+	var b bytes.Buffer
+	w := func(v any) { binary.Write(&b, le, v) }
+	w(elf.Header64{
+		Ident:     [elf.EI_NIDENT]byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS64), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)},
+		Type:      uint16(elf.ET_REL),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     64,
+		Shoff:     shoff,
+		Ehsize:    64,
+		Phentsize: 56,
+		Phnum:     PN_XNUM,
+		Shentsize: 64,
+		Shstrndx:  uint16(elf.SHN_XINDEX),
+	})
+	w(elf.Prog64{Type: uint32(elf.PT_LOAD), Flags: uint32(elf.PF_R), Align: 8})
+	b.WriteString(shstrtab)
+	b.WriteString(strtab)
+	w(elf.Sym64{})
+	w(elf.Sym64{Name: 1, Info: elf.ST_INFO(elf.STB_GLOBAL, elf.STT_OBJECT), Shndx: uint16(elf.SHN_XINDEX)})
+	w([]uint32{0, target})
+
+	symtab, str, shstr := shnum-4, shnum-3, shnum-1
+	w(elf.Section64{Size: uint64(shnum), Link: shstr, Info: 1})
+	for i := uint32(1); i < symtab; i++ {
+		w(elf.Section64{Name: 1, Type: uint32(elf.SHT_PROGBITS), Flags: uint64(elf.SHF_ALLOC)})
+	}
+	w(elf.Section64{Name: 4, Type: uint32(elf.SHT_SYMTAB), Off: symtabOff, Size: 2 * 24, Link: str, Info: 1, Addralign: 8, Entsize: 24})
+	w(elf.Section64{Name: 12, Type: uint32(elf.SHT_STRTAB), Off: 64 + 56 + uint64(len(shstrtab)), Size: uint64(len(strtab)), Addralign: 1})
+	w(elf.Section64{Name: 20, Type: uint32(elf.SHT_SYMTAB_SHNDX), Off: shndxOff, Size: 2 * 4, Link: symtab, Addralign: 4, Entsize: 4})
+	w(elf.Section64{Name: 34, Type: uint32(elf.SHT_STRTAB), Off: 64 + 56, Size: uint64(len(shstrtab)), Addralign: 1})
+	return b.Bytes()
+}
+
+func TestExtendedNumbering(t *testing.T) {
+	/* past SHN_LORESERVE, neither count nor index fits 16 bits */
+	const shnum, target = 0xff10, 0xff08
+	elfFs, err := NewFile(bytes.NewReader(xnumFile(shnum, target)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := elfFs.Hdr
+	if h.SectionCount != shnum || h.ShstrIndex != shnum-1 || h.SegmentCount != 1 {
+		t.Errorf("SectionCount %d, ShstrIndex %d, SegmentCount %d, want %d, %d, 1", h.SectionCount, h.ShstrIndex, h.SegmentCount, shnum, shnum-1)
+	}
+	if got := len(elfFs.ElfSections.Section); got != shnum {
+		t.Errorf("%d sections, want %d", got, shnum)
+	}
+	if got := len(elfFs.ProgHeaders); got != 1 {
+		t.Errorf("%d program headers, want 1", got)
+	}
+	if got := elfFs.ElfSections.SectionName[shnum-2]; got != ".symtab_shndx" {
+		t.Errorf("section %d is %q, want .symtab_shndx", shnum-2, got)
+	}
+
+	if len(elfFs.Symbols) != 2 {
+		t.Fatalf("%d symbols, want 2", len(elfFs.Symbols))
+	}
+	sym := elfFs.Symbols[1]
+	if sym.Name != "x" || sym.Section != target || sym.SectionName != ".s" {
+		t.Errorf("symbol %q in section %d %q, want x in %d .s", sym.Name, sym.Section, sym.SectionName, target)
+	}
+}
@@ -0,0 +1,291 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"fmt"
+)
+
+// HashTable is a decoded .hash (SysV) or .gnu.hash table, the index the
+// dynamic linker looks symbols of DynSymbols up in.
+type HashTable struct {
+	GNU     bool
+	Section uint32 // the section it was read from
+	Buckets []uint32
+
+	// Chains is the chain array of a SysV table, one entry per symbol.
+	// For GNU it holds the hash values of the symbols from SymOffset on,
+	// the lowest bit marking the end of a bucket's chain.
+	Chains []uint32
+
+	SymOffset  uint32   // GNU: index of the first hashed symbol
+	BloomShift uint32   // GNU
+	Bloom      []uint64 // GNU: the bloom filter, words of BloomBits bits
+	BloomBits  uint32
+}
+
+// HashProblem is an exported .dynsym entry that a lookup through the hash
+// table would not find.
+type HashProblem struct {
+	Symbol uint32
+	Name   string
+	Reason string
+}
+
+func (elfFs *ELFFile) getHashTables() error {
+	elfFs.Hash, elfFs.GNUHash = nil, nil
+
+	if ndx := elfFs.sectionOfType(elf.SHT_HASH); ndx != 0 {
+		h, err := elfFs.sysvHashTable(ndx)
+		if err := elfFs.anomaly(err); err != nil {
+			return err
+		}
+		elfFs.Hash = h
+	}
+
+	if ndx := elfFs.sectionOfType(elf.SHT_GNU_HASH); ndx != 0 {
+		h, err := elfFs.gnuHashTable(ndx)
+		if err := elfFs.anomaly(err); err != nil {
+			return err
+		}
+		elfFs.GNUHash = h
+	}
+	return nil
+}
+
+// hashWords reads a hash section as 32-bit words, or 64-bit ones for the
+// SysV tables of 64-bit Alpha and s390 binaries.
+func (elfFs *ELFFile) hashWords(ndx uint32, structure string, wide bool) ([]uint64, error) {
+	data, err := elfFs.sectionTable(structure, ndx)
+	if err != nil {
+		return nil, err
+	}
+
+	size := 4
+	if wide {
+		size = 8
+	}
+	bo := elfFs.FileHdr.Endianness
+	words := make([]uint64, len(data)/size)
+	for i := range words {
+		if wide {
+			words[i] = bo.Uint64(data[i*8:])
+		} else {
+			words[i] = uint64(bo.Uint32(data[i*4:]))
+		}
+	}
+	return words, nil
+}
+
+func (elfFs *ELFFile) sysvHashTable(ndx uint32) (*HashTable, error) {
+	structure := "hash table " + elfFs.ElfSections.SectionName[ndx]
+	wide := elfFs.FileHdr.Arch == elf.ELFCLASS64 &&
+		(elfFs.FileHdr.Machine == elf.EM_ALPHA || elfFs.FileHdr.Machine == elf.EM_S390)
+	words, err := elfFs.hashWords(ndx, structure, wide)
+	if err != nil {
+		return nil, err
+	}
+
+	off := elfFs.ElfSections.Section[ndx].Off
+	if len(words) < 2 {
+		return nil, newFormatError(ErrTruncated, off, structure, "no room for nbucket and nchain")
+	}
+	nbucket, nchain := words[0], words[1]
+	if nbucket+nchain > uint64(len(words)-2) {
+		return nil, newFormatError(ErrTruncated, off, structure, "%d buckets and %d chains, room for %d", nbucket, nchain, len(words)-2)
+	}
+
+	h := &HashTable{Section: ndx, Buckets: make([]uint32, nbucket), Chains: make([]uint32, nchain)}
+	for i := range h.Buckets {
+		h.Buckets[i] = uint32(words[2+i])
+	}
+	for i := range h.Chains {
+		h.Chains[i] = uint32(words[2+nbucket+uint64(i)])
+	}
+	return h, nil
+}
+
+func (elfFs *ELFFile) gnuHashTable(ndx uint32) (*HashTable, error) {
+	structure := "gnu hash table " + elfFs.ElfSections.SectionName[ndx]
+	words, err := elfFs.hashWords(ndx, structure, false)
+	if err != nil {
+		return nil, err
+	}
+
+	off := elfFs.ElfSections.Section[ndx].Off
+	if len(words) < 4 {
+		return nil, newFormatError(ErrTruncated, off, structure, "no room for the header")
+	}
+	h := &HashTable{GNU: true, Section: ndx, SymOffset: uint32(words[1]), BloomShift: uint32(words[3]), BloomBits: 64}
+	nbuckets, bloomSize := words[0], words[2]
+
+	/* bloom words are as wide as an address, two table words each on 64-bit */
+	bloomWords := bloomSize * 2
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		h.BloomBits, bloomWords = 32, bloomSize
+	}
+	if bloomWords+nbuckets > uint64(len(words)-4) {
+		return nil, newFormatError(ErrTruncated, off, structure, "%d bloom words and %d buckets, room for %d words", bloomSize, nbuckets, len(words)-4)
+	}
+
+	h.Bloom = make([]uint64, bloomSize)
+	for i := range h.Bloom {
+		if h.BloomBits == 32 {
+			h.Bloom[i] = words[4+i]
+			continue
+		}
+		lo, hi := words[4+2*i], words[5+2*i]
+		if elf.Data(elfFs.Ident[elf.EI_DATA]) == elf.ELFDATA2MSB {
+			lo, hi = hi, lo
+		}
+		h.Bloom[i] = hi<<32 | lo
+	}
+
+	/* the chain runs to the end of the section, one hash per symbol */
+	rest := words[4+bloomWords:]
+	h.Buckets = make([]uint32, nbuckets)
+	for i := range h.Buckets {
+		h.Buckets[i] = uint32(rest[i])
+	}
+	h.Chains = make([]uint32, len(rest)-int(nbuckets))
+	for i := range h.Chains {
+		h.Chains[i] = uint32(rest[int(nbuckets)+i])
+	}
+	return h, nil
+}
+
+// HashName hashes a symbol name the way the table does: the classic
+// elf_hash for SysV, Bernstein's h*33+c for GNU.
+func (h *HashTable) HashName(name string) uint32 {
+	if h.GNU {
+		hash := uint32(5381)
+		for i := 0; i < len(name); i++ {
+			hash = hash*33 + uint32(name[i])
+		}
+		return hash
+	}
+
+	var hash uint32
+	for i := 0; i < len(name); i++ {
+		hash = hash<<4 + uint32(name[i])
+		g := hash & 0xf0000000
+		hash ^= g >> 24
+		hash &^= g
+	}
+	return hash
+}
+
+// Chain lists the symbol indexes on the chain of bucket b, in the order
+// the dynamic linker visits them. A corrupted chain ends where it leaves
+// the table or starts going around in circles.
+func (h *HashTable) Chain(b int) []uint32 {
+	if b < 0 || b >= len(h.Buckets) {
+		return nil
+	}
+
+	var syms []uint32
+	if !h.GNU {
+		for ndx := h.Buckets[b]; ndx != 0 && ndx < uint32(len(h.Chains)); ndx = h.Chains[ndx] {
+			if len(syms) == len(h.Chains) {
+				break
+			}
+			syms = append(syms, ndx)
+		}
+		return syms
+	}
+
+	ndx := h.Buckets[b]
+	if ndx == 0 || ndx < h.SymOffset {
+		return nil
+	}
+	for i := ndx - h.SymOffset; i < uint32(len(h.Chains)); i++ {
+		syms = append(syms, h.SymOffset+i)
+		if h.Chains[i]&1 != 0 {
+			break
+		}
+	}
+	return syms
+}
+
+// ChainLengths is the length of every bucket's chain.
+func (h *HashTable) ChainLengths() []int {
+	lengths := make([]int, len(h.Buckets))
+	for b := range h.Buckets {
+		lengths[b] = len(h.Chain(b))
+	}
+	return lengths
+}
+
+// bucket is the bucket a name with this hash is on, -1 for a table
+// without buckets.
+func (h *HashTable) bucket(hash uint32) int {
+	if len(h.Buckets) == 0 {
+		return -1
+	}
+	return int(hash % uint32(len(h.Buckets)))
+}
+
+// bloomAccepts tests both bits a GNU hash sets in the bloom filter.
+func (h *HashTable) bloomAccepts(hash uint32) bool {
+	if len(h.Bloom) == 0 {
+		return false
+	}
+	/* the word index is masked like ld.so does, which assumes a power of 2 */
+	word := h.Bloom[(hash/h.BloomBits)&uint32(len(h.Bloom)-1)%uint32(len(h.Bloom))]
+	mask := uint64(1)<<(hash%h.BloomBits) | uint64(1)<<((hash>>h.BloomShift)%h.BloomBits)
+	return word&mask == mask
+}
+
+// CheckHashTable looks every exported (defined, non-local) symbol of
+// DynSymbols up through h and reports the ones the dynamic linker
+// would miss, with the step that loses them.
+func (elfFs *ELFFile) CheckHashTable(h *HashTable) []HashProblem {
+	var problems []HashProblem
+	for _, sym := range elfFs.DynSymbols {
+		if sym.Index == 0 || elf.SectionIndex(sym.Shndx) == elf.SHN_UNDEF || sym.Bind() == elf.STB_LOCAL {
+			continue
+		}
+		if reason := h.unreachable(sym); reason != "" {
+			problems = append(problems, HashProblem{sym.Index, sym.Name, reason})
+		}
+	}
+	return problems
+}
+
+// unreachable is why a lookup of sym's name doesn't end at sym, or "".
+func (h *HashTable) unreachable(sym Symbol) string {
+	hash := h.HashName(sym.Name)
+	b := h.bucket(hash)
+	if b < 0 {
+		return "the table has no buckets"
+	}
+
+	if !h.GNU {
+		if sym.Index >= uint32(len(h.Chains)) {
+			return fmt.Sprintf("index beyond the %d chain entries", len(h.Chains))
+		}
+		for _, ndx := range h.Chain(b) {
+			if ndx == sym.Index {
+				return ""
+			}
+		}
+		return fmt.Sprintf("not on the chain of bucket %d", b)
+	}
+
+	switch {
+	case sym.Index < h.SymOffset:
+		return fmt.Sprintf("index below the first hashed symbol %d", h.SymOffset)
+	case sym.Index-h.SymOffset >= uint32(len(h.Chains)):
+		return fmt.Sprintf("index beyond the %d chain entries", len(h.Chains))
+	case !h.bloomAccepts(hash):
+		return "rejected by the bloom filter"
+	}
+	for _, ndx := range h.Chain(b) {
+		if ndx == sym.Index {
+			if v := h.Chains[ndx-h.SymOffset]; v|1 != hash|1 {
+				return fmt.Sprintf("chain has hash 0x%08x, name hashes to 0x%08x", v, hash)
+			}
+			return ""
+		}
+	}
+	return fmt.Sprintf("not on the chain of bucket %d", b)
+}
@@ -0,0 +1,162 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"fmt"
+)
+
+// SymbolLookup traces the lookup of a name through a hash table the way
+// ld.so does it for dlsym, or for dlvsym when Version is set.
+type SymbolLookup struct {
+	Name    string
+	Version string
+	Table   *HashTable
+	Hash    uint32
+	Bucket  int // -1 if the lookup ended before picking one
+
+	// Candidates are the chain entries looked at, in order, each with the
+	// reason it was passed over; the one returned has none.
+	Candidates []LookupCandidate
+
+	Found  bool
+	Symbol Symbol
+	Reason string // why nothing was found
+}
+
+// LookupCandidate is a .dynsym entry on the chain a lookup walked.
+type LookupCandidate struct {
+	Symbol Symbol
+	Reason string
+}
+
+/* the symbol types check_match lets through */
+var lookupTypes = map[elf.SymType]bool{
+	elf.STT_NOTYPE: true, elf.STT_OBJECT: true, elf.STT_FUNC: true,
+	elf.STT_COMMON: true, elf.STT_TLS: true, elf.SymType(10): true, // STT_GNU_IFUNC
+}
+
+// LookupSymbol looks name up through the table the dynamic linker uses,
+// .gnu.hash when there is one, else .hash.
+func (elfFs *ELFFile) LookupSymbol(name, version string) (SymbolLookup, error) {
+	h := elfFs.GNUHash
+	if h == nil {
+		h = elfFs.Hash
+	}
+	if h == nil {
+		return SymbolLookup{}, newFormatError(ErrNoSection, 0, "hash table", "no .gnu.hash or .hash")
+	}
+	return elfFs.LookupSymbolIn(h, name, version), nil
+}
+
+// LookupSymbolIn looks name up through h: the bloom filter of a GNU
+// table, the bucket, then every chain entry until one passes ld.so's
+// checks on hash, value, type, name, version and binding. Without a
+// version a lone non-hidden versioned definition is accepted too, as
+// dlsym does.
+func (elfFs *ELFFile) LookupSymbolIn(h *HashTable, name, version string) SymbolLookup {
+	l := SymbolLookup{Name: name, Version: version, Table: h, Hash: h.HashName(name), Bucket: -1}
+	if len(h.Buckets) == 0 {
+		l.Reason = "the table has no buckets"
+		return l
+	}
+	if h.GNU && !h.bloomAccepts(l.Hash) {
+		l.Reason = "rejected by the bloom filter"
+		return l
+	}
+	l.Bucket = h.bucket(l.Hash)
+
+	versioned, numVersions := -1, 0
+	for _, ndx := range h.Chain(l.Bucket) {
+		c := LookupCandidate{Symbol: Symbol{Index: ndx}}
+		if ndx < uint32(len(elfFs.DynSymbols)) {
+			c.Symbol = elfFs.DynSymbols[ndx]
+		}
+
+		switch {
+		case h.GNU && h.Chains[ndx-h.SymOffset]|1 != l.Hash|1:
+			c.Reason = fmt.Sprintf("hash 0x%08x differs", h.Chains[ndx-h.SymOffset])
+		case ndx >= uint32(len(elfFs.DynSymbols)):
+			c.Reason = fmt.Sprintf("index beyond the %d entries of .dynsym", len(elfFs.DynSymbols))
+		default:
+			c.Reason = elfFs.lookupMismatch(c.Symbol, name, version)
+		}
+
+		/* without a version, remember the one default version there may be */
+		if c.Reason == "" && version == "" && ndx < uint32(len(elfFs.Versym)) && elfFs.Versym[ndx]&^VERSYM_HIDDEN > VER_NDX_GLOBAL {
+			ver, hidden, _ := elfFs.SymbolVersion(ndx)
+			if hidden {
+				c.Reason = fmt.Sprintf("hidden version %s, only dlvsym finds it", ver)
+			} else {
+				c.Reason = fmt.Sprintf("version %s, taken if it is the only versioned match", ver)
+				if numVersions == 0 {
+					versioned = len(l.Candidates)
+				}
+				numVersions++
+			}
+		}
+
+		l.Candidates = append(l.Candidates, c)
+		if c.Reason == "" {
+			return elfFs.lookupFound(l, len(l.Candidates)-1)
+		}
+	}
+
+	switch {
+	case numVersions == 1:
+		return elfFs.lookupFound(l, versioned)
+	case numVersions > 1:
+		l.Reason = fmt.Sprintf("%d versioned matches and no unversioned one", numVersions)
+	case len(l.Candidates) == 0:
+		l.Reason = fmt.Sprintf("bucket %d is empty", l.Bucket)
+	default:
+		l.Reason = fmt.Sprintf("nothing on the chain of bucket %d matches", l.Bucket)
+	}
+	return l
+}
+
+// lookupFound ends a lookup at candidate i. ld.so stops at the first
+// match, but only uses it if it is global, weak or unique.
+func (elfFs *ELFFile) lookupFound(l SymbolLookup, i int) SymbolLookup {
+	c := &l.Candidates[i]
+	switch c.Symbol.Bind() {
+	case elf.STB_GLOBAL, elf.STB_WEAK, elf.SymBind(10): // STB_GNU_UNIQUE
+		c.Reason = ""
+		l.Found, l.Symbol = true, c.Symbol
+	default:
+		c.Reason = fmt.Sprintf("binding %s, only global, weak and unique symbols are used", c.Symbol.Bind())
+		l.Reason = "the matching entry is not global"
+	}
+	return l
+}
+
+// lookupMismatch is why ld.so's check_match turns sym down for name and
+// version, or "". Absolute symbols may be 0, like the ones naming a
+// version.
+func (elfFs *ELFFile) lookupMismatch(sym Symbol, name, version string) string {
+	switch {
+	case sym.Value == 0 && sym.Type() != elf.STT_TLS && elf.SectionIndex(sym.Shndx) == elf.SHN_UNDEF:
+		return "undefined"
+	case sym.Value == 0 && sym.Type() != elf.STT_TLS && elf.SectionIndex(sym.Shndx) != elf.SHN_ABS:
+		return "value is 0"
+	case !lookupTypes[sym.Type()]:
+		return fmt.Sprintf("type %s can't be looked up", sym.Type())
+	case sym.Name != name:
+		return fmt.Sprintf("name is %s", sym.Name)
+	}
+
+	/* dlvsym wants exactly this version, unless there is no version information */
+	if version == "" || elfFs.Versym == nil {
+		return ""
+	}
+	if sym.Index >= uint32(len(elfFs.Versym)) {
+		return "no .gnu.version entry"
+	}
+	ver, _, ok := elfFs.SymbolVersion(sym.Index)
+	switch {
+	case !ok:
+		return "unversioned"
+	case ver != version:
+		return fmt.Sprintf("version %s", ver)
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"testing"
+)
+
+// testdata/v.so is v.c linked with gcc -shared -fPIC -Wl,--version-script=v.map:
+// foo@V1 is hidden, foo@@V2 the default, V1 and V2 are the absolute
+// symbols naming the versions.
+func TestLookupSymbol(t *testing.T) {
+	elfFs, err := Open("testdata/v.so")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer elfFs.Close()
+
+	tests := []struct {
+		name, version string
+		found         bool
+		index         uint32
+	}{
+		{"V1", "", true, 8}, // SHN_ABS with value 0 is no undefined symbol
+		{"V2", "", true, 10},
+		{"V1", "V1", true, 8},
+		{"foo_old", "", true, 9},
+		{"foo", "", false, 0}, // two default versions to choose from
+		{"foo", "V1", true, 5},
+		{"foo", "V2", true, 6},
+		{"foo", "V3", false, 0},
+		{"nosuch", "", false, 0},
+	}
+	for _, tt := range tests {
+		l, err := elfFs.LookupSymbol(tt.name, tt.version)
+		if err != nil {
+			t.Fatalf("LookupSymbol(%q, %q): %v", tt.name, tt.version, err)
+		}
+		if l.Found != tt.found || l.Found && l.Symbol.Index != tt.index {
+			t.Errorf("LookupSymbol(%q, %q) = found %v [%d] (%s), want found %v [%d]",
+				tt.name, tt.version, l.Found, l.Symbol.Index, l.Reason, tt.found, tt.index)
+		}
+	}
+}
+
+func TestLookupMismatch(t *testing.T) {
+	elfFs := &ELFFile{}
+	object := elf.ST_INFO(elf.STB_GLOBAL, elf.STT_OBJECT)
+	tests := []struct {
+		sym  Symbol
+		want string
+	}{
+		{Symbol{Name: "a", Info: object, Shndx: 0xfff1}, ""}, // SHN_ABS
+		{Symbol{Name: "a", Info: object, Shndx: 0}, "undefined"},
+		{Symbol{Name: "a", Info: object, Shndx: 5}, "value is 0"},
+		{Symbol{Name: "a", Info: object, Shndx: 5, Value: 0x10}, ""},
+		{Symbol{Name: "a", Info: elf.ST_INFO(elf.STB_GLOBAL, elf.STT_TLS), Shndx: 5}, ""}, // at offset 0 of the TLS block
+		{Symbol{Name: "b", Info: object, Shndx: 5, Value: 0x10}, "name is b"},
+	}
+	for _, tt := range tests {
+		if got := elfFs.lookupMismatch(tt.sym, "a", ""); got != tt.want {
+			t.Errorf("lookupMismatch(%+v) = %q, want %q", tt.sym, got, tt.want)
+		}
+	}
+}
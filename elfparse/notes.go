@@ -0,0 +1,367 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Note is one entry of a note section or PT_NOTE segment.
+type Note struct {
+	Owner string
+	Type  uint32
+	Desc  []byte
+	Off   uint64 // file offset of the note header
+}
+
+// NoteTable groups the notes of one SHT_NOTE section, or of one PT_NOTE
+// segment when the file has no usable section headers.
+type NoteTable struct {
+	Name  string // section name, empty for a segment
+	Off   uint64
+	Size  uint64
+	Notes []Note
+}
+
+const (
+	NT_GNU_ABI_TAG         = 1
+	NT_GNU_HWCAP           = 2
+	NT_GNU_BUILD_ID        = 3
+	NT_GNU_GOLD_VERSION    = 4
+	NT_GNU_PROPERTY_TYPE_0 = 5
+
+	NT_GO_BUILD_ID = 4
+
+	NT_FREEBSD_ABI_TAG     = 1
+	NT_FREEBSD_NOINIT_TAG  = 2
+	NT_FREEBSD_ARCH_TAG    = 3
+	NT_FREEBSD_FEATURE_CTL = 4
+
+	NT_ANDROID_TYPE_IDENT  = 1
+	NT_ANDROID_TYPE_KUSER  = 3
+	NT_ANDROID_TYPE_MEMTAG = 4
+)
+
+const (
+	GNU_PROPERTY_STACK_SIZE            = 1
+	GNU_PROPERTY_NO_COPY_ON_PROTECTED  = 2
+	GNU_PROPERTY_1_NEEDED              = 0xb0008000
+	GNU_PROPERTY_AARCH64_FEATURE_1_AND = 0xc0000000
+	GNU_PROPERTY_X86_FEATURE_1_AND     = 0xc0000002
+	GNU_PROPERTY_X86_FEATURE_2_NEEDED  = 0xc0008001
+	GNU_PROPERTY_X86_ISA_1_NEEDED      = 0xc0008002
+	GNU_PROPERTY_X86_FEATURE_2_USED    = 0xc0010001
+	GNU_PROPERTY_X86_ISA_1_USED        = 0xc0010002
+)
+
+func (elfFs *ELFFile) getNotes() error {
+	elfFs.Notes = nil
+
+	s := elfFs.ElfSections.Section
+	for sNdx := range s {
+		if s[sNdx].Type != elf.SHT_NOTE || s[sNdx].Synthetic {
+			continue
+		}
+		table := NoteTable{Name: elfFs.ElfSections.SectionName[sNdx], Off: s[sNdx].Off, Size: s[sNdx].Size}
+		data, err := elfFs.sectionTable("notes "+table.Name, uint32(sNdx))
+		if err != nil {
+			return err
+		}
+		if err := elfFs.loadNotes(&table, data, s[sNdx].Addralign); err != nil {
+			return err
+		}
+		elfFs.Notes = append(elfFs.Notes, table)
+	}
+	if len(elfFs.Notes) > 0 {
+		return nil
+	}
+
+	/* stripped section headers still leave the loader's view */
+	for _, prog := range elfFs.ProgHeaders {
+		if prog.Type != elf.PT_NOTE {
+			continue
+		}
+		table := NoteTable{Off: prog.Off, Size: prog.Filesz}
+		data, err := elfFs.readTable("notes", table.Off, table.Size)
+		if err != nil {
+			return err
+		}
+		if err := elfFs.loadNotes(&table, data, prog.Align); err != nil {
+			return err
+		}
+		elfFs.Notes = append(elfFs.Notes, table)
+	}
+	return nil
+}
+
+// loadNotes walks the notes in data, the contents of table. Descriptor and next note start 4
+// byte aligned, or 8 when the containing section or segment is 8 byte
+// aligned as GNU property notes on 64-bit are.
+func (elfFs *ELFFile) loadNotes(table *NoteTable, data []byte, align uint64) error {
+	structure := "notes"
+	if table.Name != "" {
+		structure = "notes " + table.Name
+	}
+
+	pad := uint64(4)
+	if align == 8 {
+		pad = 8
+	}
+	bo := elfFs.FileHdr.Endianness
+
+	for pos := uint64(0); pos < uint64(len(data)); {
+		if uint64(len(data))-pos < 12 {
+			return elfFs.anomaly(newFormatError(ErrTruncated, table.Off+pos, structure, "%d bytes left for a note header", uint64(len(data))-pos))
+		}
+		namesz, descsz := uint64(bo.Uint32(data[pos:])), uint64(bo.Uint32(data[pos+4:]))
+		note := Note{Type: bo.Uint32(data[pos+8:]), Off: table.Off + pos}
+
+		nameOff := pos + 12
+		descOff := alignUp(nameOff+namesz, pad)
+		end := alignUp(descOff+descsz, pad)
+		if descOff+descsz > uint64(len(data)) {
+			return elfFs.anomaly(newFormatError(ErrOutOfRange, note.Off, structure,
+				"namesz %d descsz %d, 0x%x bytes left", namesz, descsz, uint64(len(data))-pos))
+		}
+
+		note.Owner = strings.TrimRight(string(data[nameOff:nameOff+namesz]), "\x00")
+		note.Desc = data[descOff : descOff+descsz]
+		table.Notes = append(table.Notes, note)
+		pos = end
+	}
+	return nil
+}
+
+func alignUp(v, align uint64) uint64 {
+	return (v + align - 1) &^ (align - 1)
+}
+
+// NoteTypeName names the note type, which only means something together
+// with the owner.
+func (elfFs *ELFFile) NoteTypeName(n Note) string {
+	var names map[uint32]string
+	switch n.Owner {
+	case "GNU":
+		names = gnuNoteTypes
+	case "Go":
+		names = goNoteTypes
+	case "FreeBSD":
+		names = freebsdNoteTypes
+	case "Android":
+		names = androidNoteTypes
+	case "CORE", "LINUX":
+		names = coreNoteTypes
+	}
+	if name, ok := names[n.Type]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown note type: (0x%08x)", n.Type)
+}
+
+var gnuNoteTypes = map[uint32]string{
+	NT_GNU_ABI_TAG:         "NT_GNU_ABI_TAG (ABI version tag)",
+	NT_GNU_HWCAP:           "NT_GNU_HWCAP (DSO-supplied software HWCAP info)",
+	NT_GNU_BUILD_ID:        "NT_GNU_BUILD_ID (unique build ID bitstring)",
+	NT_GNU_GOLD_VERSION:    "NT_GNU_GOLD_VERSION (gold version)",
+	NT_GNU_PROPERTY_TYPE_0: "NT_GNU_PROPERTY_TYPE_0",
+}
+
+var goNoteTypes = map[uint32]string{
+	NT_GO_BUILD_ID: "GO BUILDID",
+}
+
+var freebsdNoteTypes = map[uint32]string{
+	NT_FREEBSD_ABI_TAG:     "NT_FREEBSD_ABI_TAG",
+	NT_FREEBSD_NOINIT_TAG:  "NT_FREEBSD_NOINIT_TAG",
+	NT_FREEBSD_ARCH_TAG:    "NT_FREEBSD_ARCH_TAG",
+	NT_FREEBSD_FEATURE_CTL: "NT_FREEBSD_FEATURE_CTL (FreeBSD feature control)",
+}
+
+var androidNoteTypes = map[uint32]string{
+	NT_ANDROID_TYPE_IDENT:  "NT_ANDROID_TYPE_IDENT",
+	NT_ANDROID_TYPE_KUSER:  "NT_ANDROID_TYPE_KUSER",
+	NT_ANDROID_TYPE_MEMTAG: "NT_ANDROID_TYPE_MEMTAG",
+}
+
+var coreNoteTypes = map[uint32]string{
+	1:          "NT_PRSTATUS (prstatus structure)",
+	2:          "NT_FPREGSET (floating point registers)",
+	3:          "NT_PRPSINFO (prpsinfo structure)",
+	4:          "NT_TASKSTRUCT (task structure)",
+	6:          "NT_AUXV (auxiliary vector)",
+	0x202:      "NT_X86_XSTATE (x86 XSAVE extended state)",
+	0x46494c45: "NT_FILE (mapped files)",
+	0x53494749: "NT_SIGINFO (siginfo_t data)",
+}
+
+// NoteDescription decodes the descriptor of the notes this package knows
+// about into readable lines. Anything else is returned as a hex dump.
+func (elfFs *ELFFile) NoteDescription(n Note) []string {
+	bo := elfFs.FileHdr.Endianness
+	word := func(i int) uint32 { return bo.Uint32(n.Desc[i*4:]) }
+
+	switch {
+	case n.Owner == "GNU" && n.Type == NT_GNU_BUILD_ID:
+		return []string{"Build ID: " + hex.EncodeToString(n.Desc)}
+
+	case n.Owner == "GNU" && n.Type == NT_GNU_ABI_TAG && len(n.Desc) >= 16:
+		os := fmt.Sprintf("Unknown (%d)", word(0))
+		switch word(0) {
+		case 0:
+			os = "Linux"
+		case 1:
+			os = "Hurd"
+		case 2:
+			os = "Solaris"
+		case 3:
+			os = "FreeBSD"
+		}
+		return []string{fmt.Sprintf("OS: %s, ABI: %d.%d.%d", os, word(1), word(2), word(3))}
+
+	case n.Owner == "GNU" && n.Type == NT_GNU_GOLD_VERSION:
+		return []string{"Version: " + strings.TrimRight(string(n.Desc), "\x00")}
+
+	case n.Owner == "GNU" && n.Type == NT_GNU_PROPERTY_TYPE_0:
+		return elfFs.gnuProperties(n.Desc)
+
+	case n.Owner == "Go" && n.Type == NT_GO_BUILD_ID:
+		return []string{"Build ID: " + strings.TrimRight(string(n.Desc), "\x00")}
+
+	case n.Owner == "FreeBSD" && n.Type == NT_FREEBSD_ABI_TAG && len(n.Desc) >= 4:
+		return []string{fmt.Sprintf("ABI tag: %d", word(0))}
+
+	case n.Owner == "FreeBSD" && n.Type == NT_FREEBSD_ARCH_TAG:
+		return []string{"Arch tag: " + strings.TrimRight(string(n.Desc), "\x00")}
+
+	case n.Owner == "FreeBSD" && n.Type == NT_FREEBSD_FEATURE_CTL && len(n.Desc) >= 4:
+		return []string{"Features: " + flagNames(uint64(word(0)), freebsdFeatures)}
+
+	case n.Owner == "Android" && n.Type == NT_ANDROID_TYPE_IDENT && len(n.Desc) >= 4:
+		return []string{fmt.Sprintf("description data: API level %d", word(0))}
+
+	case n.Owner == "Android" && n.Type == NT_ANDROID_TYPE_MEMTAG && len(n.Desc) >= 4:
+		mode := "none"
+		switch word(0) & 3 {
+		case 1:
+			mode = "async"
+		case 2:
+			mode = "sync"
+		case 3:
+			mode = "unknown"
+		}
+		return []string{fmt.Sprintf("Tagging Mode: %s, Heap: %t, Stack: %t", mode, word(0)&4 != 0, word(0)&8 != 0)}
+	}
+
+	if len(n.Desc) == 0 {
+		return nil
+	}
+	return []string{"description data: " + fmt.Sprintf("% x", n.Desc)}
+}
+
+// gnuProperties decodes the pr_type/pr_datasz/pr_data array of a
+// NT_GNU_PROPERTY_TYPE_0 note, each entry padded to the word size.
+func (elfFs *ELFFile) gnuProperties(desc []byte) []string {
+	bo := elfFs.FileHdr.Endianness
+	pad := uint64(8)
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		pad = 4
+	}
+
+	var props []string
+	for pos := uint64(0); pos < uint64(len(desc)); {
+		if uint64(len(desc))-pos < 8 {
+			props = append(props, fmt.Sprintf("<corrupt length: %#x>", uint64(len(desc))-pos))
+			break
+		}
+		typ, size := bo.Uint32(desc[pos:]), uint64(bo.Uint32(desc[pos+4:]))
+		pos += 8
+		if size > uint64(len(desc))-pos {
+			props = append(props, fmt.Sprintf("<corrupt length: %#x>", size))
+			break
+		}
+		data := desc[pos : pos+size]
+		pos += alignUp(size, pad)
+
+		var val uint64
+		switch size {
+		case 4:
+			val = uint64(bo.Uint32(data))
+		case 8:
+			val = bo.Uint64(data)
+		}
+
+		x86 := elfFs.Hdr.Machine == elf.EM_X86_64 || elfFs.Hdr.Machine == elf.EM_386
+		switch {
+		case typ == GNU_PROPERTY_STACK_SIZE:
+			props = append(props, fmt.Sprintf("stack size: %#x", val))
+		case typ == GNU_PROPERTY_NO_COPY_ON_PROTECTED:
+			props = append(props, "no copy on protected")
+		case typ == GNU_PROPERTY_1_NEEDED:
+			props = append(props, "1_needed: "+flagNames(val, gnuProperty1Needed))
+		case typ == GNU_PROPERTY_X86_FEATURE_1_AND && x86:
+			props = append(props, "x86 feature: "+flagNames(val, x86Feature1))
+		case typ == GNU_PROPERTY_X86_ISA_1_NEEDED && x86:
+			props = append(props, "x86 ISA needed: "+flagNames(val, x86ISA1))
+		case typ == GNU_PROPERTY_X86_ISA_1_USED && x86:
+			props = append(props, "x86 ISA used: "+flagNames(val, x86ISA1))
+		case typ == GNU_PROPERTY_X86_FEATURE_2_NEEDED && x86:
+			props = append(props, "x86 feature needed: "+flagNames(val, x86Feature2))
+		case typ == GNU_PROPERTY_X86_FEATURE_2_USED && x86:
+			props = append(props, "x86 feature used: "+flagNames(val, x86Feature2))
+		case typ == GNU_PROPERTY_AARCH64_FEATURE_1_AND && elfFs.Hdr.Machine == elf.EM_AARCH64:
+			props = append(props, "AArch64 feature: "+flagNames(val, aarch64Feature1))
+		default:
+			props = append(props, fmt.Sprintf("<unknown type %#x data: % x>", typ, data))
+		}
+	}
+	if len(props) == 0 {
+		return nil
+	}
+	props[0] = "Properties: " + props[0]
+	for i := 1; i < len(props); i++ {
+		props[i] = "            " + props[i]
+	}
+	return props
+}
+
+type flagName struct {
+	bit  uint64
+	name string
+}
+
+// flagNames lists the names of the bits set in val, unknown bits in hex.
+func flagNames(val uint64, names []flagName) string {
+	if val == 0 {
+		return "<None>"
+	}
+	var out []string
+	for _, f := range names {
+		if val&f.bit != 0 {
+			out = append(out, f.name)
+			val &^= f.bit
+		}
+	}
+	if val != 0 {
+		out = append(out, fmt.Sprintf("<unknown: %#x>", val))
+	}
+	return strings.Join(out, ", ")
+}
+
+var x86Feature1 = []flagName{{1, "IBT"}, {2, "SHSTK"}, {4, "LAM_U48"}, {8, "LAM_U57"}}
+
+var x86ISA1 = []flagName{{1, "x86-64-baseline"}, {2, "x86-64-v2"}, {4, "x86-64-v3"}, {8, "x86-64-v4"}}
+
+var x86Feature2 = []flagName{
+	{1, "x86"}, {2, "x87"}, {4, "MMX"}, {8, "XMM"}, {0x10, "YMM"}, {0x20, "ZMM"},
+	{0x40, "FXSR"}, {0x80, "XSAVE"}, {0x100, "XSAVEOPT"}, {0x200, "XSAVEC"}, {0x400, "TMM"}, {0x800, "MASK"},
+}
+
+var aarch64Feature1 = []flagName{{1, "BTI"}, {2, "PAC"}, {4, "GCS"}}
+
+var gnuProperty1Needed = []flagName{{1, "indirect external access"}}
+
+var freebsdFeatures = []flagName{
+	{1, "ASLR disabled"}, {2, "PROTMAX disabled"}, {4, "STKGAP disabled"},
+	{8, "WXNEEDED"}, {0x10, "LA48"}, {0x20, "ASG disabled"},
+}
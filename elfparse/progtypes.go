@@ -0,0 +1,92 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"fmt"
+)
+
+var genericProgTypes = map[elf.ProgType]string{
+	elf.PT_NULL:    "PT_NULL",
+	elf.PT_LOAD:    "PT_LOAD",
+	elf.PT_DYNAMIC: "PT_DYNAMIC",
+	elf.PT_INTERP:  "PT_INTERP",
+	elf.PT_NOTE:    "PT_NOTE",
+	elf.PT_SHLIB:   "PT_SHLIB",
+	elf.PT_PHDR:    "PT_PHDR",
+	elf.PT_TLS:     "PT_TLS",
+}
+
+/* debug/elf's String picks one name where the OS range overlaps */
+var osProgTypes = map[elf.ProgType]string{
+	elf.PT_GNU_EH_FRAME:      "PT_GNU_EH_FRAME",
+	elf.PT_GNU_STACK:         "PT_GNU_STACK",
+	elf.PT_GNU_RELRO:         "PT_GNU_RELRO",
+	elf.PT_GNU_PROPERTY:      "PT_GNU_PROPERTY",
+	PT_GNU_SFRAME:            "PT_GNU_SFRAME",
+	elf.PT_PAX_FLAGS:         "PT_PAX_FLAGS",
+	elf.PT_OPENBSD_RANDOMIZE: "PT_OPENBSD_RANDOMIZE",
+	elf.PT_OPENBSD_WXNEEDED:  "PT_OPENBSD_WXNEEDED",
+	elf.ProgType(0x65a3dbe8): "PT_OPENBSD_NOBTCFI",
+	elf.PT_OPENBSD_BOOTDATA:  "PT_OPENBSD_BOOTDATA",
+	elf.PT_SUNWSTACK:         "PT_SUNWSTACK",
+	elf.ProgType(0x6ffffffa): "PT_SUNWBSS",
+	elf.ProgType(0x6464e550): "PT_SUNW_UNWIND",
+}
+
+var procProgTypes = map[elf.Machine]map[elf.ProgType]string{
+	elf.EM_ARM: {
+		elf.PT_ARM_ARCHEXT: "PT_ARM_ARCHEXT",
+		elf.PT_ARM_EXIDX:   "PT_ARM_EXIDX",
+	},
+	elf.EM_AARCH64: {
+		elf.PT_AARCH64_ARCHEXT:   "PT_AARCH64_ARCHEXT",
+		elf.PT_AARCH64_UNWIND:    "PT_AARCH64_UNWIND",
+		elf.ProgType(0x70000002): "PT_AARCH64_MEMTAG_MTE",
+	},
+	elf.EM_MIPS: {
+		elf.PT_MIPS_REGINFO:  "PT_MIPS_REGINFO",
+		elf.PT_MIPS_RTPROC:   "PT_MIPS_RTPROC",
+		elf.PT_MIPS_OPTIONS:  "PT_MIPS_OPTIONS",
+		elf.PT_MIPS_ABIFLAGS: "PT_MIPS_ABIFLAGS",
+	},
+	elf.EM_RISCV: {
+		elf.ProgType(0x70000003): "PT_RISCV_ATTRIBUTES",
+	},
+	elf.EM_S390: {
+		elf.PT_S390_PGSTE: "PT_S390_PGSTE",
+	},
+	elf.EM_IA_64: {
+		elf.ProgType(0x70000000): "PT_IA_64_ARCHEXT",
+		elf.ProgType(0x70000001): "PT_IA_64_UNWIND",
+	},
+	elf.EM_PARISC: {
+		elf.ProgType(0x70000000): "PT_PARISC_ARCHEXT",
+		elf.ProgType(0x70000001): "PT_PARISC_UNWIND",
+	},
+}
+
+// ProgTypeName names p_type for this binary. Processor specific types are
+// looked up by e_machine, since every architecture reuses the same values.
+func (elfFs *ELFFile) ProgTypeName(t elf.ProgType) string {
+	if name, ok := genericProgTypes[t]; ok {
+		return name
+	}
+
+	switch {
+	case t >= elf.PT_LOPROC && t <= elf.PT_HIPROC:
+		if name, ok := procProgTypes[elfFs.FileHdr.Machine][t]; ok {
+			return name
+		}
+		return fmt.Sprintf("LOPROC+0x%x", uint32(t-elf.PT_LOPROC))
+
+	case t >= elf.PT_GNU_MBIND_LO && t <= elf.PT_GNU_MBIND_HI:
+		return fmt.Sprintf("PT_GNU_MBIND+0x%x", uint32(t-elf.PT_GNU_MBIND_LO))
+
+	case t >= elf.PT_LOOS && t <= elf.PT_HIOS:
+		if name, ok := osProgTypes[t]; ok {
+			return name
+		}
+		return fmt.Sprintf("LOOS+0x%x", uint32(t-elf.PT_LOOS))
+	}
+	return fmt.Sprintf("<unknown>: 0x%x", uint32(t))
+}
@@ -0,0 +1,234 @@
+package elfparse
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"io"
+)
+
+// SHT_RELR is the packed relative relocation section debug/elf doesn't
+// define.
+const SHT_RELR elf.SectionType = 19
+
+func (elfFs *ELFFile) getRelocations() error {
+	elfFs.Rels = make(map[uint32][]Reloc)
+	elfFs.Relrs = make(map[uint32]RelrTable)
+
+	s := elfFs.ElfSections.Section
+	for sNdx := uint32(0); sNdx < uint32(len(s)); sNdx++ {
+		var load func(uint32) ([]Reloc, error)
+		switch s[sNdx].Type {
+		case SHT_RELR, SHT_ANDROID_RELR:
+			relr, err := elfFs.loadRelr(sNdx)
+			if err := elfFs.anomaly(err); err != nil {
+				return err
+			}
+			elfFs.Relrs[sNdx] = relr
+			continue
+		case elf.SHT_REL, elf.SHT_RELA:
+			load = elfFs.loadRelocations
+		case SHT_ANDROID_REL, SHT_ANDROID_RELA:
+			load = elfFs.loadAndroidRelocations
+		default:
+			continue
+		}
+
+		rels, err := load(sNdx)
+		if err := elfFs.anomaly(err); err != nil {
+			return err
+		}
+		elfFs.Rels[sNdx] = rels
+	}
+	return nil
+}
+
+func (elfFs *ELFFile) loadRelocations(sNdx uint32) ([]Reloc, error) {
+	sec := elfFs.ElfSections.Section[sNdx]
+	isRela := sec.Type == elf.SHT_RELA
+	structure := "relocation table " + elfFs.ElfSections.SectionName[sNdx]
+
+	var err error
+	relSize := elfFs.relSize(isRela)
+	if sec.Entsize != 0 && sec.Entsize != relSize {
+		if relSize, err = elfFs.entrySize(structure, sec.Off, sec.Entsize, elfFs.relSize(isRela)); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := elfFs.sectionTable(structure, sNdx)
+	if err != nil {
+		return nil, err
+	}
+
+	rels := make([]Reloc, uint64(len(data))/relSize)
+	for i := range rels {
+		elfFs.decodeReloc(bytes.NewReader(data[uint64(i)*relSize:]), isRela, &rels[i])
+	}
+	return rels, nil
+}
+
+// loadRelr expands a SHT_RELR or SHT_ANDROID_RELR section. An even word
+// is the address of a relocation and the base for the bitmaps after it; in
+// an odd word every bit but the lowest stands for one of the next 31 or 63
+// words after the base.
+func (elfFs *ELFFile) loadRelr(sNdx uint32) (RelrTable, error) {
+	sec := elfFs.ElfSections.Section[sNdx]
+	structure := "relr table " + elfFs.ElfSections.SectionName[sNdx]
+
+	wordSize := uint64(8)
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		wordSize = 4
+	}
+	entSize := wordSize
+	if sec.Entsize != 0 && sec.Entsize != wordSize {
+		var err error
+		if entSize, err = elfFs.entrySize(structure, sec.Off, sec.Entsize, wordSize); err != nil {
+			return RelrTable{}, err
+		}
+	}
+
+	data, err := elfFs.sectionTable(structure, sNdx)
+	if err != nil {
+		return RelrTable{}, err
+	}
+
+	bo := elfFs.FileHdr.Endianness
+	relr := RelrTable{Words: int(uint64(len(data)) / entSize)}
+	var base uint64
+	for i := 0; i < relr.Words; i++ {
+		var word uint64
+		if wordSize == 8 {
+			word = bo.Uint64(data[uint64(i)*entSize:])
+		} else {
+			word = uint64(bo.Uint32(data[uint64(i)*entSize:]))
+		}
+
+		if word&1 == 0 {
+			relr.Addrs = append(relr.Addrs, word)
+			base = word + wordSize
+			continue
+		}
+		for bit := uint64(0); word>>1>>bit != 0; bit++ {
+			if word>>1>>bit&1 != 0 {
+				relr.Addrs = append(relr.Addrs, base+bit*wordSize)
+			}
+		}
+		base += (wordSize*8 - 1) * wordSize
+	}
+	return relr, nil
+}
+
+// RelativeType is the machine's R_*_RELATIVE relocation, the one every
+// address of a RELR table stands for.
+func (elfFs *ELFFile) RelativeType() (uint32, bool) {
+	switch elfFs.FileHdr.Machine {
+	case elf.EM_X86_64:
+		return uint32(elf.R_X86_64_RELATIVE), true
+	case elf.EM_386:
+		return uint32(elf.R_386_RELATIVE), true
+	case elf.EM_ARM:
+		return uint32(elf.R_ARM_RELATIVE), true
+	case elf.EM_AARCH64:
+		return uint32(elf.R_AARCH64_RELATIVE), true
+	case elf.EM_PPC:
+		return uint32(elf.R_PPC_RELATIVE), true
+	case elf.EM_PPC64:
+		return uint32(elf.R_PPC64_RELATIVE), true
+	case elf.EM_RISCV:
+		return uint32(elf.R_RISCV_RELATIVE), true
+	case elf.EM_S390:
+		return uint32(elf.R_390_RELATIVE), true
+	case elf.EM_SPARCV9:
+		return uint32(elf.R_SPARC_RELATIVE), true
+	}
+	return 0, false
+}
+
+func (elfFs *ELFFile) relSize(isRela bool) uint64 {
+	switch {
+	case elfFs.FileHdr.Arch == elf.ELFCLASS32 && isRela:
+		return 12
+	case elfFs.FileHdr.Arch == elf.ELFCLASS32:
+		return 8
+	case isRela:
+		return 24
+	default:
+		return 16
+	}
+}
+
+// decodeReloc reads the next Elf{32,64}_Rel or Elf{32,64}_Rela from r and
+// splits its info field into symbol index and type.
+func (elfFs *ELFFile) decodeReloc(r io.Reader, isRela bool, rel *Reloc) error {
+	bo := elfFs.FileHdr.Endianness
+	rel.HasAddend = isRela
+
+	switch elfFs.FileHdr.Arch {
+	case elf.ELFCLASS32:
+		if isRela {
+			var r32 elf.Rela32
+			if err := binary.Read(r, bo, &r32); err != nil {
+				return err
+			}
+			rel.Off, rel.Info, rel.Addend = uint64(r32.Off), uint64(r32.Info), int64(r32.Addend)
+		} else {
+			var r32 elf.Rel32
+			if err := binary.Read(r, bo, &r32); err != nil {
+				return err
+			}
+			rel.Off, rel.Info = uint64(r32.Off), uint64(r32.Info)
+		}
+		rel.Sym = elf.R_SYM32(uint32(rel.Info))
+		rel.Type = elf.R_TYPE32(uint32(rel.Info))
+
+	case elf.ELFCLASS64:
+		if isRela {
+			var r64 elf.Rela64
+			if err := binary.Read(r, bo, &r64); err != nil {
+				return err
+			}
+			rel.Off, rel.Info, rel.Addend = r64.Off, r64.Info, r64.Addend
+		} else {
+			var r64 elf.Rel64
+			if err := binary.Read(r, bo, &r64); err != nil {
+				return err
+			}
+			rel.Off, rel.Info = r64.Off, r64.Info
+		}
+		rel.Sym = elf.R_SYM64(rel.Info)
+		rel.Type = elf.R_TYPE64(rel.Info)
+	}
+	return nil
+}
+
+// RelocSymbol returns the symbol rel refers to. relNdx is the index of the
+// relocation section rel was read from; its sh_link decides whether the
+// symbol lives in the dynamic or the static symbol table.
+func (elfFs *ELFFile) RelocSymbol(relNdx uint32, rel Reloc) (Symbol, error) {
+	if rel.Sym == 0 {
+		return Symbol{}, nil
+	}
+
+	sec := elfFs.ElfSections.Section[relNdx]
+	var symbols []Symbol
+	linked := false
+
+	switch sec.Link {
+	case 0:
+	case elfFs.sectionOfType(elf.SHT_DYNSYM):
+		symbols, linked = elfFs.DynSymbols, true
+	case elfFs.sectionOfType(elf.SHT_SYMTAB):
+		symbols, linked = elfFs.Symbols, true
+	}
+	if !linked {
+		return Symbol{}, newFormatError(ErrBadLink, sec.Off, "relocation table "+elfFs.ElfSections.SectionName[relNdx],
+			"sh_link %d is not a symbol table", sec.Link)
+	}
+
+	if rel.Sym >= uint32(len(symbols)) {
+		return Symbol{}, newFormatError(ErrOutOfRange, sec.Off, "relocation table "+elfFs.ElfSections.SectionName[relNdx],
+			"symbol index %d, %s has %d entries", rel.Sym, elfFs.ElfSections.SectionName[sec.Link], len(symbols))
+	}
+	return symbols[rel.Sym], nil
+}
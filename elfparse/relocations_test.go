@@ -0,0 +1,71 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestLoadRelr(t *testing.T) {
+	words := func(class elf.Class, ws ...uint64) []byte {
+		var out []byte
+		for _, w := range ws {
+			if class == elf.ELFCLASS32 {
+				out = binary.LittleEndian.AppendUint32(out, uint32(w))
+			} else {
+				out = binary.LittleEndian.AppendUint64(out, w)
+			}
+		}
+		return out
+	}
+	tests := []struct {
+		name  string
+		class elf.Class
+		words []uint64
+		want  []uint64
+	}{
+		{"addresses", elf.ELFCLASS64, []uint64{0x1000, 0x2000}, []uint64{0x1000, 0x2000}},
+		{"bitmap", elf.ELFCLASS64, []uint64{0x1000, 0b1011}, []uint64{0x1000, 0x1008, 0x1018}},
+		{"bitmaps", elf.ELFCLASS64, []uint64{0x1000, 0b11, 0b11}, []uint64{0x1000, 0x1008, 0x1008 + 63*8}},
+		{"bitmap after bitmap gap", elf.ELFCLASS64, []uint64{0x1000, 1, 0b101}, []uint64{0x1000, 0x1008 + 63*8 + 8}},
+		{"32-bit bitmaps", elf.ELFCLASS32, []uint64{0x1000, 0b11, 0x80000001}, []uint64{0x1000, 0x1004, 0x1004 + 31*4 + 30*4}},
+		{"empty", elf.ELFCLASS64, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relr, err := tableFile(tt.class, SHT_RELR, words(tt.class, tt.words...)).loadRelr(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if relr.Words != len(tt.words) || !reflect.DeepEqual(relr.Addrs, tt.want) {
+				t.Errorf("loadRelr = %d words %#x, want %d words %#x", relr.Words, relr.Addrs, len(tt.words), tt.want)
+			}
+		})
+	}
+}
+
+// testdata/relr.so is r.c linked with gcc -shared -fPIC
+// -Wl,-z,pack-relative-relocs, arelr.so is it with .relr.dyn and its
+// dynamic tags turned into their Android counterparts.
+func TestRelrAndroid(t *testing.T) {
+	relr := func(name string, typ elf.SectionType) RelrTable {
+		elfFs, err := Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer elfFs.Close()
+		ndx := elfFs.SectionNdx(".relr.dyn")
+		if ndx == 0 || elfFs.ElfSections.Section[ndx].Type != typ {
+			t.Fatalf("%s: no .relr.dyn of type %v", name, typ)
+		}
+		return elfFs.Relrs[ndx]
+	}
+	want, got := relr("testdata/relr.so", SHT_RELR), relr("testdata/arelr.so", SHT_ANDROID_RELR)
+	if len(want.Addrs) < 60 {
+		t.Fatalf("relr.so has %d RELR relocations", len(want.Addrs))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SHT_ANDROID_RELR = %+v, want %+v", got, want)
+	}
+}
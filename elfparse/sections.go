@@ -0,0 +1,200 @@
+package elfparse
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+//Section Header Table Offset = Shoff
+//Number of Section Header Table Entries = SectionCount
+//Size per entry in Section Header Table = Shentsize
+//Calculate the size of Section Header Table = SectionCount * Shentsize
+
+func (elfFs *ELFFile) getSections() error {
+	h := elfFs.Hdr
+
+	elfFs.ElfSections.Section = nil
+	elfFs.ElfSections.SectionName = nil
+	if h.SectionCount == 0 {
+		return nil
+	}
+
+	shentsize, err := elfFs.entrySize("section header table", h.Shoff, uint64(h.Shentsize), elfFs.shdrSize())
+	if err != nil {
+		return err
+	}
+
+	shdrTable, err := elfFs.readTable("section header table", h.Shoff, shentsize*uint64(h.SectionCount))
+	if err != nil {
+		return err
+	}
+
+	/* a resilient read may have returned fewer entries than e_shnum claims */
+	sections := make([]Section, uint64(len(shdrTable))/shentsize)
+	for i := range sections {
+		entry := bytes.NewReader(shdrTable[uint64(i)*shentsize:])
+		elfFs.decodeSections(entry, sections[i:i+1])
+	}
+	elfFs.ElfSections.Section = sections
+	elfFs.ElfSections.SectionName = make([]string, len(sections))
+	if len(sections) == 0 {
+		return nil
+	}
+
+	shstrndx := h.ShstrIndex
+	if shstrndx >= uint32(len(sections)) {
+		err := newFormatError(ErrOutOfRange, h.Shoff, "section header table", "e_shstrndx %d, %d sections", h.ShstrIndex, len(sections))
+		if !elfFs.resilient {
+			return err
+		}
+		if shstrndx = elfFs.guessShstrndx(); shstrndx != 0 {
+			err.Detail += fmt.Sprintf(", using section %d as string table", shstrndx)
+		}
+		elfFs.anomaly(err)
+		if shstrndx == 0 {
+			return nil
+		}
+	}
+	shstrtabOff := sections[shstrndx].Off
+	shstrtabSize := sections[shstrndx].Size
+
+	shstrtab, err := elfFs.readTable("section header string table", shstrtabOff, shstrtabSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range sections {
+		sIndex := sections[i].Name
+		if sIndex != 0 && sIndex >= uint32(len(shstrtab)) {
+			err := newFormatError(ErrBadStringIndex, shstrtabOff, "section header string table", "name of section %d at 0x%x, table is 0x%x bytes", i, sIndex, len(shstrtab))
+			if err := elfFs.anomaly(err); err != nil {
+				return err
+			}
+			continue
+		}
+		elfFs.ElfSections.SectionName[i] = getSectionName(sIndex, shstrtab)
+	}
+	return nil
+}
+
+// guessShstrndx looks for the section header string table when e_shstrndx
+// can't be trusted: the SHT_STRTAB section whose own name resolves to
+// ".shstrtab" inside itself.
+func (elfFs *ELFFile) guessShstrndx() uint32 {
+	for i, sec := range elfFs.ElfSections.Section {
+		if sec.Type != elf.SHT_STRTAB || i == 0 {
+			continue
+		}
+		const name = ".shstrtab"
+		if uint64(sec.Name)+uint64(len(name)) > sec.Size {
+			continue
+		}
+		buf, err := elfFs.readBytes("section header string table", sec.Off+uint64(sec.Name), uint64(len(name)))
+		if err == nil && string(buf) == name {
+			return uint32(i)
+		}
+	}
+	return 0
+}
+
+func (elfFs *ELFFile) shdrSize() uint64 {
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		return 40
+	}
+	return 64
+}
+
+// decodeSections fills sections with consecutive Elf32_Shdr or Elf64_Shdr
+// entries read from r.
+func (elfFs *ELFFile) decodeSections(r io.Reader, sections []Section) error {
+	switch elfFs.FileHdr.Arch {
+	case elf.ELFCLASS32:
+		raw := make([]elf.Section32, len(sections))
+		if err := binary.Read(r, elfFs.FileHdr.Endianness, raw); err != nil {
+			return err
+		}
+		for i, s := range raw {
+			sections[i] = Section{
+				Name:      s.Name,
+				Type:      elf.SectionType(s.Type),
+				Flags:     elf.SectionFlag(s.Flags),
+				Addr:      uint64(s.Addr),
+				Off:       uint64(s.Off),
+				Size:      uint64(s.Size),
+				Link:      s.Link,
+				Info:      s.Info,
+				Addralign: uint64(s.Addralign),
+				Entsize:   uint64(s.Entsize),
+			}
+		}
+
+	case elf.ELFCLASS64:
+		raw := make([]elf.Section64, len(sections))
+		if err := binary.Read(r, elfFs.FileHdr.Endianness, raw); err != nil {
+			return err
+		}
+		for i, s := range raw {
+			sections[i] = Section{
+				Name:      s.Name,
+				Type:      elf.SectionType(s.Type),
+				Flags:     elf.SectionFlag(s.Flags),
+				Addr:      s.Addr,
+				Off:       s.Off,
+				Size:      s.Size,
+				Link:      s.Link,
+				Info:      s.Info,
+				Addralign: s.Addralign,
+				Entsize:   s.Entsize,
+			}
+		}
+	}
+	return nil
+}
+
+// SectionNdx returns the index of the first section called name, or 0
+// (SHN_UNDEF) if there is none.
+func (elfFs *ELFFile) SectionNdx(name string) uint32 {
+	var ndx uint32
+	for ndx = 0; ndx < uint32(len(elfFs.ElfSections.SectionName)); ndx++ {
+		if elfFs.ElfSections.SectionName[ndx] == name {
+			return ndx
+		}
+	}
+	return uint32(0)
+}
+
+// SectionsByType returns the indexes of every section of type t.
+func (elfFs *ELFFile) SectionsByType(t elf.SectionType) []uint32 {
+
+	var indexList []uint32
+
+	s := elfFs.ElfSections.Section
+	for sNdx := uint32(0); sNdx < uint32(len(s)); sNdx++ {
+		if t == s[sNdx].Type {
+			indexList = append(indexList, sNdx)
+		}
+	}
+
+	return indexList
+}
+
+func getSectionName(sIndex uint32, sectionShstrTab []byte) string {
+	if sIndex >= uint32(len(sectionShstrTab)) {
+		return ""
+	}
+
+	end := sIndex
+	for end < uint32(len(sectionShstrTab)) {
+		if sectionShstrTab[end] == 0x0 {
+			break
+		}
+		end++
+	}
+
+	var name bytes.Buffer
+	name.Write(sectionShstrTab[sIndex:end])
+	return name.String()
+}
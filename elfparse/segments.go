@@ -0,0 +1,200 @@
+package elfparse
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+)
+
+func (elfFs *ELFFile) getProgHeaders() error {
+	header := elfFs.Hdr
+	elfFs.ProgHeaders = nil
+	if header.SegmentCount == 0 {
+		return nil
+	}
+
+	phentsize, err := elfFs.entrySize("program header table", header.Phoff, uint64(header.Phentsize), elfFs.phdrSize())
+	if err != nil {
+		return err
+	}
+
+	buffer, err := elfFs.readTable("program header table", header.Phoff, uint64(header.SegmentCount)*phentsize)
+	if err != nil {
+		return err
+	}
+
+	elfFs.ProgHeaders = make([]Segment, uint64(len(buffer))/phentsize)
+	for i := range elfFs.ProgHeaders {
+		buf := bytes.NewBuffer(buffer[uint64(i)*phentsize : uint64(i+1)*phentsize])
+		if err := elfFs.decodeSegment(buf, &elfFs.ProgHeaders[i]); err != nil {
+			off := header.Phoff + uint64(i)*phentsize
+			return ioError(err, off, fmt.Sprintf("program header %d", i))
+		}
+	}
+	return nil
+}
+
+func (elfFs *ELFFile) phdrSize() uint64 {
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		return 32
+	}
+	return 56
+}
+
+// decodeSegment reads a single Elf32_Phdr or Elf64_Phdr from buf.
+func (elfFs *ELFFile) decodeSegment(buf *bytes.Buffer, seg *Segment) error {
+	switch elfFs.FileHdr.Arch {
+	case elf.ELFCLASS32:
+		var entry elf.Prog32
+		if err := binary.Read(buf, elfFs.FileHdr.Endianness, &entry); err != nil {
+			return err
+		}
+		*seg = Segment{
+			Type:   elf.ProgType(entry.Type),
+			Flags:  elf.ProgFlag(entry.Flags),
+			Off:    uint64(entry.Off),
+			Vaddr:  uint64(entry.Vaddr),
+			Paddr:  uint64(entry.Paddr),
+			Filesz: uint64(entry.Filesz),
+			Memsz:  uint64(entry.Memsz),
+			Align:  uint64(entry.Align),
+		}
+
+	case elf.ELFCLASS64:
+		var entry elf.Prog64
+		if err := binary.Read(buf, elfFs.FileHdr.Endianness, &entry); err != nil {
+			return err
+		}
+		*seg = Segment{
+			Type:   elf.ProgType(entry.Type),
+			Flags:  elf.ProgFlag(entry.Flags),
+			Off:    entry.Off,
+			Vaddr:  entry.Vaddr,
+			Paddr:  entry.Paddr,
+			Filesz: entry.Filesz,
+			Memsz:  entry.Memsz,
+			Align:  entry.Align,
+		}
+	}
+	return nil
+}
+
+// VaddrToOff maps a virtual address to the file offset a PT_LOAD segment
+// loads it from. It reports false for addresses that aren't backed by the
+// file, such as .bss.
+func (elfFs *ELFFile) VaddrToOff(addr uint64) (uint64, bool) {
+	for _, prog := range elfFs.ProgHeaders {
+		if prog.Type != elf.PT_LOAD {
+			continue
+		}
+		if addr >= prog.Vaddr && addr-prog.Vaddr < prog.Filesz {
+			return addr - prog.Vaddr + prog.Off, true
+		}
+	}
+	return 0, false
+}
+
+// ImageBase is the address the first PT_LOAD segment is linked at, rounded
+// down to its alignment. An image loaded at base has a load bias of
+// base - ImageBase(), 0 for executables at their link address.
+func (elfFs *ELFFile) ImageBase() uint64 {
+	for _, prog := range elfFs.ProgHeaders {
+		if prog.Type != elf.PT_LOAD {
+			continue
+		}
+		if prog.Align > 1 && prog.Align&(prog.Align-1) == 0 {
+			return prog.Vaddr &^ (prog.Align - 1)
+		}
+		return prog.Vaddr
+	}
+	return 0
+}
+
+// PT_GNU_SFRAME is missing from debug/elf.
+const PT_GNU_SFRAME elf.ProgType = 0x6474e554
+
+// SegmentSections lists the sections segment i contains, by the rules GNU
+// readelf uses: TLS sections only count towards PT_TLS, PT_LOAD and
+// PT_GNU_RELRO, loadable segments only hold SHF_ALLOC sections, .tbss only
+// takes up room in PT_TLS and empty sections don't sit on the edge of
+// PT_DYNAMIC or PT_NOTE.
+func (elfFs *ELFFile) SegmentSections(i int) []uint32 {
+	seg := elfFs.ProgHeaders[i]
+	var ndx []uint32
+	for sNdx := 1; sNdx < len(elfFs.ElfSections.Section); sNdx++ {
+		sec := elfFs.ElfSections.Section[sNdx]
+		if tbssSpecial(sec, seg) || !sectionInSegment(sec, seg) {
+			continue
+		}
+		ndx = append(ndx, uint32(sNdx))
+	}
+	return ndx
+}
+
+func tbssSpecial(sec Section, seg Segment) bool {
+	return sec.Flags&elf.SHF_TLS != 0 && sec.Type == elf.SHT_NOBITS && seg.Type != elf.PT_TLS
+}
+
+// sectionInSegment is binutils' ELF_SECTION_IN_SEGMENT_STRICT. Like there,
+// the unsigned "- 1" of an empty segment wraps around on purpose.
+func sectionInSegment(sec Section, seg Segment) bool {
+	tls := sec.Flags&elf.SHF_TLS != 0
+	alloc := sec.Flags&elf.SHF_ALLOC != 0
+	nobits := sec.Type == elf.SHT_NOBITS
+
+	size := sec.Size
+	if tbssSpecial(sec, seg) {
+		size = 0
+	}
+
+	switch {
+	case tls && seg.Type != elf.PT_TLS && seg.Type != elf.PT_GNU_RELRO && seg.Type != elf.PT_LOAD:
+		return false
+	case !tls && (seg.Type == elf.PT_TLS || seg.Type == elf.PT_PHDR):
+		return false
+	}
+
+	if !alloc {
+		switch {
+		case seg.Type == elf.PT_LOAD, seg.Type == elf.PT_DYNAMIC, seg.Type == elf.PT_GNU_EH_FRAME,
+			seg.Type == elf.PT_GNU_STACK, seg.Type == elf.PT_GNU_RELRO, seg.Type == PT_GNU_SFRAME,
+			seg.Type >= elf.PT_GNU_MBIND_LO && seg.Type <= elf.PT_GNU_MBIND_HI:
+			return false
+		}
+	}
+
+	if !nobits {
+		if sec.Off < seg.Off || sec.Off-seg.Off > seg.Filesz-1 || sec.Off-seg.Off+size > seg.Filesz {
+			return false
+		}
+	}
+
+	if alloc {
+		if sec.Addr < seg.Vaddr || sec.Addr-seg.Vaddr > seg.Memsz-1 || sec.Addr-seg.Vaddr+size > seg.Memsz {
+			return false
+		}
+	}
+
+	if (seg.Type == elf.PT_DYNAMIC || seg.Type == elf.PT_NOTE) && sec.Size == 0 && seg.Memsz != 0 {
+		inFile := nobits || (sec.Off > seg.Off && sec.Off-seg.Off < seg.Filesz)
+		inMem := !alloc || (sec.Addr > seg.Vaddr && sec.Addr-seg.Vaddr < seg.Memsz)
+		return inFile && inMem
+	}
+	return true
+}
+
+// Interp returns the program interpreter PT_INTERP names.
+func (elfFs *ELFFile) Interp() (string, bool) {
+	for _, prog := range elfFs.ProgHeaders {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		data, err := elfFs.readBytes("program interpreter", prog.Off, prog.Filesz)
+		if err != nil {
+			return "", false
+		}
+		return getSectionName(0, data), true
+	}
+	return "", false
+}
@@ -0,0 +1,155 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// testdata/seg-i386 and seg-x86_64 are seg.c linked with gcc -O2 -fPIE
+// -pie -nostdlib -Wl,-z,noexecstack, the others are written by mkseg.go.
+// The segments and their sections are what readelf -W -l prints.
+func TestSegments(t *testing.T) {
+	type seg struct {
+		typ      string
+		sections string
+	}
+	rwSegments := []seg{
+		{"PT_DYNAMIC", ".dynamic"},
+		{"PT_NOTE", ".note.gnu.property"},
+		{"PT_GNU_PROPERTY", ".note.gnu.property"},
+	}
+	tail := []seg{
+		{"PT_TLS", ".tdata .tbss"},
+		{"PT_GNU_EH_FRAME", ".eh_frame_hdr"},
+		{"PT_GNU_STACK", ""},
+		{"PT_GNU_RELRO", ".tdata .dynamic"},
+	}
+	/* the layout mkseg.go writes, around the machine's own sections */
+	generated := func(extra ...string) []seg {
+		text := append([]string{".interp", ".note.gnu.property"}, extra...)
+		text = append(text, ".dynstr", ".text", ".eh_frame_hdr", ".eh_frame")
+		segs := []seg{
+			{"PT_PHDR", ""},
+			{"PT_INTERP", ".interp"},
+			{"PT_LOAD", strings.Join(text, " ")},
+			{"PT_LOAD", ".tdata .dynamic .data .bss"},
+		}
+		return append(segs, rwSegments...)
+	}
+	linked := []seg{
+		{"PT_PHDR", ""},
+		{"PT_INTERP", ".interp"},
+		{"PT_LOAD", ".interp .note.gnu.build-id .gnu.hash .dynsym .dynstr"},
+		{"PT_LOAD", ".text"},
+		{"PT_LOAD", ".eh_frame_hdr .eh_frame"},
+		{"PT_LOAD", ".tdata .dynamic"},
+		{"PT_DYNAMIC", ".dynamic"},
+		{"PT_NOTE", ".note.gnu.build-id"},
+	}
+	join := func(parts ...[]seg) []seg {
+		var segs []seg
+		for _, p := range parts {
+			segs = append(segs, p...)
+		}
+		return segs
+	}
+
+	tests := []struct {
+		file    string
+		class   elf.Class
+		order   binary.ByteOrder
+		machine elf.Machine
+		interp  string
+		rwLoad  Segment // the writable PT_LOAD
+		segs    []seg
+	}{
+		{
+			file: "seg-i386", class: elf.ELFCLASS32, order: binary.LittleEndian, machine: elf.EM_386,
+			interp: "/lib/ld-linux.so.2",
+			rwLoad: Segment{Vaddr: 0x3f94, Filesz: 0x6c, Memsz: 0x6c},
+			segs:   join(linked, tail),
+		},
+		{
+			file: "seg-x86_64", class: elf.ELFCLASS64, order: binary.LittleEndian, machine: elf.EM_X86_64,
+			interp: "/lib64/ld-linux-x86-64.so.2",
+			rwLoad: Segment{Vaddr: 0x3f2c, Filesz: 0xd4, Memsz: 0xd4},
+			segs:   join(linked, tail),
+		},
+		{
+			file: "seg-mips32be", class: elf.ELFCLASS32, order: binary.BigEndian, machine: elf.EM_MIPS,
+			interp: "/lib/ld.so.1",
+			rwLoad: Segment{Vaddr: 0x4102b4, Filesz: 0x20, Memsz: 0x120},
+			segs: join(generated(".MIPS.abiflags", ".reginfo"), []seg{
+				{"PT_MIPS_ABIFLAGS", ".MIPS.abiflags"},
+				{"PT_MIPS_REGINFO", ".reginfo"},
+			}, tail),
+		},
+		{
+			file: "seg-mips64be", class: elf.ELFCLASS64, order: binary.BigEndian, machine: elf.EM_MIPS,
+			interp: "/lib64/ld.so.1",
+			rwLoad: Segment{Vaddr: 0x410418, Filesz: 0x40, Memsz: 0x140},
+			segs: join(generated(".MIPS.abiflags", ".MIPS.options"), []seg{
+				{"PT_MIPS_ABIFLAGS", ".MIPS.abiflags"},
+				{"PT_MIPS_OPTIONS", ".MIPS.options"},
+			}, tail),
+		},
+		{
+			file: "seg-s390x", class: elf.ELFCLASS64, order: binary.BigEndian, machine: elf.EM_S390,
+			interp: "/lib/ld64.so.1",
+			rwLoad: Segment{Vaddr: 0x410368, Filesz: 0x40, Memsz: 0x140},
+			segs:   join(generated(), tail),
+		},
+		{
+			file: "seg-arm", class: elf.ELFCLASS32, order: binary.LittleEndian, machine: elf.EM_ARM,
+			interp: "/lib/ld-linux-armhf.so.3",
+			rwLoad: Segment{Vaddr: 0x410284, Filesz: 0x20, Memsz: 0x120},
+			segs:   join(generated(".ARM.exidx"), []seg{{"PT_ARM_EXIDX", ".ARM.exidx"}}, tail),
+		},
+		{
+			file: "seg-aarch64", class: elf.ELFCLASS64, order: binary.LittleEndian, machine: elf.EM_AARCH64,
+			interp: "/lib/ld-linux-aarch64.so.1",
+			rwLoad: Segment{Vaddr: 0x410378, Filesz: 0x40, Memsz: 0x140},
+			segs:   join(generated(), tail),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			elfFs, err := Open("testdata/" + tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer elfFs.Close()
+
+			hdr := elfFs.FileHdr
+			if hdr.Arch != tt.class || hdr.Endianness != tt.order || hdr.Machine != tt.machine {
+				t.Errorf("header %v %v %v, want %v %v %v", hdr.Arch, hdr.Endianness, hdr.Machine, tt.class, tt.order, tt.machine)
+			}
+			if interp, ok := elfFs.Interp(); !ok || interp != tt.interp {
+				t.Errorf("Interp() = %q, %v, want %q", interp, ok, tt.interp)
+			}
+
+			if len(elfFs.ProgHeaders) != len(tt.segs) {
+				t.Fatalf("%d program headers, want %d", len(elfFs.ProgHeaders), len(tt.segs))
+			}
+			for i, prog := range elfFs.ProgHeaders {
+				var names []string
+				for _, ndx := range elfFs.SegmentSections(i) {
+					names = append(names, elfFs.ElfSections.SectionName[ndx])
+				}
+				got := seg{elfFs.ProgTypeName(prog.Type), strings.Join(names, " ")}
+				if got != tt.segs[i] {
+					t.Errorf("segment %d = %+v, want %+v", i, got, tt.segs[i])
+				}
+
+				if prog.Type == elf.PT_LOAD && prog.Flags&elf.PF_W != 0 {
+					if prog.Vaddr != tt.rwLoad.Vaddr || prog.Filesz != tt.rwLoad.Filesz || prog.Memsz != tt.rwLoad.Memsz {
+						t.Errorf("writable PT_LOAD at 0x%x, 0x%x/0x%x bytes, want 0x%x, 0x%x/0x%x", prog.Vaddr, prog.Filesz,
+							prog.Memsz, tt.rwLoad.Vaddr, tt.rwLoad.Filesz, tt.rwLoad.Memsz)
+					}
+				}
+			}
+		})
+	}
+}
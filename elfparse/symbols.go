@@ -0,0 +1,193 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"strings"
+)
+
+// getSymbols loads the first SHT_DYNSYM and SHT_SYMTAB sections, whatever
+// they are called, with the string table of their sh_link.
+func (elfFs *ELFFile) getSymbols() error {
+	if dsymtabNdx := elfFs.sectionOfType(elf.SHT_DYNSYM); dsymtabNdx != 0 {
+		dynstrNdx := elfFs.symbolStrtab(dsymtabNdx, ".dynstr")
+		if err := elfFs.anomaly(elfFs.loadSymbols(dsymtabNdx, dynstrNdx, DynSym)); err != nil {
+			return err
+		}
+	}
+
+	if symtabNdx := elfFs.sectionOfType(elf.SHT_SYMTAB); symtabNdx != 0 {
+		symstrNdx := elfFs.symbolStrtab(symtabNdx, ".strtab")
+		if err := elfFs.anomaly(elfFs.loadSymbols(symtabNdx, symstrNdx, Sym)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// symbolStrtab is the string table sh_link of symbol table ndx names, or
+// the section called name when the link isn't a SHT_STRTAB.
+func (elfFs *ELFFile) symbolStrtab(ndx uint32, name string) uint32 {
+	s := elfFs.ElfSections.Section
+	if link := s[ndx].Link; link != 0 && link < uint32(len(s)) && s[link].Type == elf.SHT_STRTAB {
+		return link
+	}
+	return elfFs.SectionNdx(name)
+}
+
+func (elfFs *ELFFile) loadSymbols(sectionNdx uint32, symstrNdx uint32, symType int) error {
+	symtab := elfFs.ElfSections.Section[sectionNdx]
+	symtabName := "symbol table " + elfFs.ElfSections.SectionName[sectionNdx]
+
+	/* a string table that isn't where its name says falls back to sh_link */
+	if symstrNdx == 0 && symtab.Link < uint32(len(elfFs.ElfSections.Section)) {
+		symstrNdx = symtab.Link
+	}
+
+	/* strtab can be either .dynstr or .strtab depending on the symbol table*/
+	strtabName := "string table " + elfFs.ElfSections.SectionName[symstrNdx]
+	strtab, err := elfFs.sectionTable(strtabName, symstrNdx)
+	if err != nil {
+		return err
+	}
+
+	symSize := elfFs.symSize()
+	if symtab.Entsize != 0 && symtab.Entsize != symSize {
+		if symSize, err = elfFs.entrySize(symtabName, symtab.Off, symtab.Entsize, elfFs.symSize()); err != nil {
+			return err
+		}
+	}
+
+	data, err := elfFs.sectionTable(symtabName, sectionNdx)
+	if err != nil {
+		return err
+	}
+	numSymbols := uint64(len(data)) / symSize
+
+	xindex, err := elfFs.symtabShndx(sectionNdx)
+	if err := elfFs.anomaly(err); err != nil {
+		return err
+	}
+
+	/* the names are slices of one copy of the string table */
+	strs := string(strtab)
+	symbols := make([]Symbol, numSymbols)
+	for i := range symbols {
+		symNdx := uint32(i)
+		sym := elfFs.decodeSymbol(data[uint64(symNdx)*symSize:])
+		sym.Index = symNdx
+		if sym.NameOff >= uint32(len(strs)) && sym.NameOff != 0 {
+			err := newFormatError(ErrBadStringIndex, symtab.Off+uint64(symNdx)*symSize, symtabName,
+				"name of symbol %d at 0x%x, %s is 0x%x bytes", symNdx, sym.NameOff, strtabName, len(strtab))
+			if err := elfFs.anomaly(err); err != nil {
+				return err
+			}
+		} else {
+			sym.Name = getSymbolName(sym.NameOff, strs)
+		}
+		if err := elfFs.anomaly(elfFs.resolveSymbolSection(&sym, xindex, symtabName)); err != nil {
+			return err
+		}
+		symbols[i] = sym
+	}
+
+	switch symType {
+	case Sym:
+		elfFs.Symbols = symbols
+	case DynSym:
+		elfFs.DynSymbols = symbols
+	}
+	return nil
+}
+
+// symtabShndx returns the SHT_SYMTAB_SHNDX section belonging to symbol
+// table ndx, which holds the section indexes too large for st_shndx.
+func (elfFs *ELFFile) symtabShndx(ndx uint32) ([]byte, error) {
+	for i, sec := range elfFs.ElfSections.Section {
+		if sec.Type == elf.SHT_SYMTAB_SHNDX && sec.Link == ndx {
+			return elfFs.sectionTable("section index table "+elfFs.ElfSections.SectionName[i], uint32(i))
+		}
+	}
+	return nil, nil
+}
+
+// resolveSymbolSection sets sym.Section and sym.SectionName from its
+// st_shndx, looking SHN_XINDEX up in the table's xindex entries.
+func (elfFs *ELFFile) resolveSymbolSection(sym *Symbol, xindex []byte, symtabName string) error {
+	sym.Section = uint32(sym.Shndx)
+	switch elf.SectionIndex(sym.Shndx) {
+	case elf.SHN_UNDEF:
+		sym.SectionName = "UND"
+		return nil
+	case elf.SHN_ABS:
+		sym.SectionName = "ABS"
+		return nil
+	case elf.SHN_COMMON:
+		sym.SectionName = "COM"
+		return nil
+	case elf.SHN_XINDEX:
+		off := uint64(sym.Index) * 4
+		if off+4 > uint64(len(xindex)) {
+			return newFormatError(ErrOutOfRange, 0, symtabName,
+				"symbol %d has SHN_XINDEX but no SHT_SYMTAB_SHNDX entry", sym.Index)
+		}
+		sym.Section = elfFs.FileHdr.Endianness.Uint32(xindex[off:])
+	}
+	if sym.Shndx < uint16(elf.SHN_LORESERVE) || elf.SectionIndex(sym.Shndx) == elf.SHN_XINDEX {
+		if sym.Section < uint32(len(elfFs.ElfSections.SectionName)) {
+			sym.SectionName = elfFs.ElfSections.SectionName[sym.Section]
+		}
+	}
+	return nil
+}
+
+// SymbolTable returns the symbols of table symType, .symtab for Sym and
+// .dynsym for DynSym.
+func (elfFs *ELFFile) SymbolTable(symType int) []Symbol {
+	if symType == DynSym {
+		return elfFs.DynSymbols
+	}
+	return elfFs.Symbols
+}
+
+func (elfFs *ELFFile) symSize() uint64 {
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		return elf.Sym32Size
+	}
+	return elf.Sym64Size
+}
+
+// decodeSymbol decodes the Elf32_Sym or Elf64_Sym at the start of b.
+func (elfFs *ELFFile) decodeSymbol(b []byte) Symbol {
+	bo := elfFs.FileHdr.Endianness
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		return Symbol{
+			NameOff: bo.Uint32(b[0:]),
+			Value:   uint64(bo.Uint32(b[4:])),
+			Size:    uint64(bo.Uint32(b[8:])),
+			Info:    b[12],
+			Other:   b[13],
+			Shndx:   bo.Uint16(b[14:]),
+		}
+	}
+	return Symbol{
+		NameOff: bo.Uint32(b[0:]),
+		Info:    b[4],
+		Other:   b[5],
+		Shndx:   bo.Uint16(b[6:]),
+		Value:   bo.Uint64(b[8:]),
+		Size:    bo.Uint64(b[16:]),
+	}
+}
+
+// getSymbolName returns the name at off in strtab. It is a slice of
+// strtab, not a copy.
+func getSymbolName(off uint32, strtab string) string {
+	if off >= uint32(len(strtab)) {
+		return ""
+	}
+	name := strtab[off:]
+	if end := strings.IndexByte(name, 0); end >= 0 {
+		name = name[:end]
+	}
+	return name
+}
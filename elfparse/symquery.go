@@ -0,0 +1,131 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// SymbolSort is the order QuerySymbols returns symbols in.
+type SymbolSort int
+
+const (
+	SortIndex SymbolSort = iota // symbol table order
+	SortAddress
+	SortSize
+	SortName
+)
+
+// SymbolQuery selects and orders the entries of a symbol table. Every
+// filter that is set must match; the zero value selects every symbol in
+// table order.
+type SymbolQuery struct {
+	Types        []elf.SymType
+	Binds        []elf.SymBind
+	Visibilities []elf.SymVis
+	Sections     []uint32 // Symbol.Section values, so SHN_UNDEF, SHN_ABS and SHN_COMMON work too
+	Defined      bool     // only symbols with a section
+	Undefined    bool     // only SHN_UNDEF symbols
+	Name         *regexp.Regexp
+	AddrRange    bool // only values in [Low, High)
+	Low, High    uint64
+	Demangle     bool // match and sort by the demangled name
+
+	Sort    SymbolSort
+	Reverse bool
+	Limit   int // at most this many symbols, 0 for all
+}
+
+// Active reports whether q filters or reorders anything.
+func (q SymbolQuery) Active() bool {
+	return len(q.Types) > 0 || len(q.Binds) > 0 || len(q.Visibilities) > 0 || len(q.Sections) > 0 ||
+		q.Defined || q.Undefined || q.Name != nil || q.AddrRange || q.Sort != SortIndex || q.Reverse || q.Limit > 0
+}
+
+func (q SymbolQuery) match(sym Symbol, name func(Symbol) string) bool {
+	if len(q.Types) > 0 && !slices.Contains(q.Types, sym.Type()) {
+		return false
+	}
+	if len(q.Binds) > 0 && !slices.Contains(q.Binds, sym.Bind()) {
+		return false
+	}
+	if len(q.Visibilities) > 0 && !slices.Contains(q.Visibilities, sym.Visibility()) {
+		return false
+	}
+	if len(q.Sections) > 0 && !slices.Contains(q.Sections, sym.Section) {
+		return false
+	}
+
+	undef := elf.SectionIndex(sym.Shndx) == elf.SHN_UNDEF
+	if q.Defined && undef || q.Undefined && !undef {
+		return false
+	}
+	if q.AddrRange && (sym.Value < q.Low || sym.Value >= q.High) {
+		return false
+	}
+	return q.Name == nil || q.Name.MatchString(name(sym))
+}
+
+// QuerySymbols returns the symbols of table symType (Sym or DynSym)
+// selected by q, in the order it asks for. Names are matched and sorted
+// without their version. Ties keep table order, also when the sort is
+// reversed. A query that selects everything returns the table itself.
+func (elfFs *ELFFile) QuerySymbols(symType int, q SymbolQuery) []Symbol {
+	if !q.Active() {
+		return elfFs.SymbolTable(symType)
+	}
+
+	name := func(sym Symbol) string { return sym.Name }
+	if q.Demangle {
+		/* demangling is slow enough to do only once per string */
+		demangled := map[uint32]string{}
+		name = func(sym Symbol) string {
+			s, ok := demangled[sym.NameOff]
+			if !ok {
+				s = Demangle(sym.Name)
+				demangled[sym.NameOff] = s
+			}
+			return s
+		}
+	}
+
+	/* in table order the first Limit matches are all that's needed */
+	inOrder := q.Sort == SortIndex && !q.Reverse
+	var syms []Symbol
+	for _, sym := range elfFs.SymbolTable(symType) {
+		if inOrder && q.Limit > 0 && len(syms) == q.Limit {
+			break
+		}
+		if q.match(sym, name) {
+			syms = append(syms, sym)
+		}
+	}
+
+	var less func(a, b Symbol) bool
+	switch q.Sort {
+	case SortAddress:
+		less = func(a, b Symbol) bool { return a.Value < b.Value }
+	case SortSize:
+		less = func(a, b Symbol) bool { return a.Size < b.Size }
+	case SortName:
+		less = func(a, b Symbol) bool { return name(a) < name(b) }
+	}
+	switch {
+	case less != nil:
+		sort.SliceStable(syms, func(i, j int) bool {
+			a, b := syms[i], syms[j]
+			if q.Reverse {
+				a, b = b, a
+			}
+			return less(a, b)
+		})
+	case q.Reverse:
+		slices.Reverse(syms)
+	}
+
+	if q.Limit > 0 && len(syms) > q.Limit {
+		syms = syms[:q.Limit]
+	}
+	return syms
+}
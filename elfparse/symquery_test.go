@@ -0,0 +1,64 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestQuerySymbols(t *testing.T) {
+	elfFs, err := Open("testdata/v.so")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer elfFs.Close()
+
+	/* the indexes of the symbols selected, see readelf -W --dyn-syms -s */
+	tests := []struct {
+		name  string
+		table int
+		q     SymbolQuery
+		want  []uint32
+	}{
+		{"everything", DynSym, SymbolQuery{}, []uint32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{"type", DynSym, SymbolQuery{Types: []elf.SymType{elf.STT_FUNC}}, []uint32{5, 6, 7, 9}},
+		{"binding", DynSym, SymbolQuery{Binds: []elf.SymBind{elf.STB_WEAK}}, []uint32{1, 2, 3, 4}},
+		{"undefined", DynSym, SymbolQuery{Undefined: true}, []uint32{0, 1, 2, 3, 4}},
+		{"defined", DynSym, SymbolQuery{Defined: true}, []uint32{5, 6, 7, 8, 9, 10}},
+		{"absolute", DynSym, SymbolQuery{Sections: []uint32{uint32(elf.SHN_ABS)}}, []uint32{8, 10}},
+		{"name without version", DynSym, SymbolQuery{Name: regexp.MustCompile("^foo$")}, []uint32{5, 6, 7}},
+		{"name and type", DynSym, SymbolQuery{Name: regexp.MustCompile("old"), Types: []elf.SymType{elf.STT_FUNC}}, []uint32{9}},
+		{"address range", DynSym, SymbolQuery{AddrRange: true, Low: 0x1100, High: 0x1110}, []uint32{5, 9}},
+		{"by address", DynSym, SymbolQuery{Sort: SortAddress}, []uint32{0, 1, 2, 3, 4, 8, 10, 6, 7, 5, 9}},
+		{"by address reversed", DynSym, SymbolQuery{Sort: SortAddress, Reverse: true}, []uint32{5, 9, 6, 7, 0, 1, 2, 3, 4, 8, 10}},
+		{"reversed", DynSym, SymbolQuery{Reverse: true}, []uint32{10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0}},
+		{"by name", DynSym, SymbolQuery{Binds: []elf.SymBind{elf.STB_GLOBAL}, Sort: SortName}, []uint32{8, 10, 5, 6, 7, 9}},
+		{"by size, limited", DynSym, SymbolQuery{Types: []elf.SymType{elf.STT_FUNC}, Sort: SortSize, Limit: 3}, []uint32{5, 6, 7}},
+		{"limited", DynSym, SymbolQuery{Binds: []elf.SymBind{elf.STB_GLOBAL}, Limit: 2}, []uint32{5, 6}},
+		{"files", Sym, SymbolQuery{Types: []elf.SymType{elf.STT_FILE}}, []uint32{1, 9, 10, 12}},
+		{"section", Sym, SymbolQuery{Sections: []uint32{11}}, []uint32{2, 3, 4, 7, 20, 21, 25, 27}},
+		{
+			name:  "type, binding and visibility",
+			table: Sym,
+			q: SymbolQuery{
+				Types:        []elf.SymType{elf.STT_OBJECT},
+				Binds:        []elf.SymBind{elf.STB_LOCAL},
+				Visibilities: []elf.SymVis{elf.STV_DEFAULT},
+			},
+			want: []uint32{5, 6, 8, 11, 13, 14, 15, 19},
+		},
+		{"hidden", Sym, SymbolQuery{Visibilities: []elf.SymVis{elf.STV_HIDDEN}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []uint32
+			for _, sym := range elfFs.QuerySymbols(tt.table, tt.q) {
+				got = append(got, sym.Index)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QuerySymbols = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"sort"
+)
+
+// synthSection is a section reconstructed from a dynamic tag, linked to
+// another synthesized section by name.
+type synthSection struct {
+	name    string
+	typ     elf.SectionType
+	flags   elf.SectionFlag
+	addr    uint64
+	size    uint64
+	entsize uint64
+	link    string
+}
+
+// synthesizeSections rebuilds the sections the dynamic linker relies on
+// from PT_DYNAMIC and the PT_LOAD mappings when the binary has no usable
+// section headers (sstrip, hand crafted malware). In resilient mode, when
+// the headers it has lack a SHT_DYNSYM, the rebuilt sections that no real
+// one covers are added to them.
+func (elfFs *ELFFile) synthesizeSections() error {
+	if len(elfFs.Dynamic) == 0 {
+		return nil
+	}
+	if _, ok := elfFs.DynValue(elf.DT_SYMTAB); !ok {
+		return nil
+	}
+	if len(elfFs.ElfSections.Section) > 0 {
+		if !elfFs.resilient || elfFs.sectionOfType(elf.SHT_DYNSYM) != 0 {
+			return nil
+		}
+		elfFs.anomaly(newFormatError(ErrNoSection, elfFs.Hdr.Shoff, "section header table",
+			"no SHT_DYNSYM despite DT_SYMTAB, adding the sections PT_DYNAMIC describes"))
+	}
+
+	wordSize := uint64(8)
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		wordSize = 4
+	}
+	var synth []synthSection
+	dyn := func(tag elf.DynTag) uint64 {
+		v, _ := elfFs.DynValue(tag)
+		return v
+	}
+	has := func(tag elf.DynTag) bool {
+		_, ok := elfFs.DynValue(tag)
+		return ok
+	}
+
+	numSyms := elfFs.dynSymCount()
+	symtab := dyn(elf.DT_SYMTAB)
+	syment := dyn(elf.DT_SYMENT)
+	if syment == 0 {
+		syment = elfFs.symSize()
+	}
+	synth = append(synth, synthSection{".dynsym", elf.SHT_DYNSYM, elf.SHF_ALLOC, symtab, numSyms * syment, syment, ".dynstr"})
+
+	if has(elf.DT_STRTAB) {
+		synth = append(synth, synthSection{".dynstr", elf.SHT_STRTAB, elf.SHF_ALLOC, dyn(elf.DT_STRTAB), dyn(elf.DT_STRSZ), 0, ""})
+	}
+
+	if has(elf.DT_HASH) {
+		nbucket, nchain := elfFs.sysvHashSize(dyn(elf.DT_HASH))
+		synth = append(synth, synthSection{".hash", elf.SHT_HASH, elf.SHF_ALLOC, dyn(elf.DT_HASH), (2 + nbucket + nchain) * 4, 4, ".dynsym"})
+	}
+
+	if has(elf.DT_GNU_HASH) {
+		size := elfFs.gnuHashSize(dyn(elf.DT_GNU_HASH), numSyms, wordSize)
+		synth = append(synth, synthSection{".gnu.hash", elf.SHT_GNU_HASH, elf.SHF_ALLOC, dyn(elf.DT_GNU_HASH), size, 0, ".dynsym"})
+	}
+
+	if has(elf.DT_VERSYM) {
+		synth = append(synth, synthSection{".gnu.version", elf.SHT_GNU_VERSYM, elf.SHF_ALLOC, dyn(elf.DT_VERSYM), numSyms * 2, 2, ".dynsym"})
+	}
+
+	if has(elf.DT_VERNEED) {
+		size := elfFs.versionTableSize(dyn(elf.DT_VERNEED), dyn(elf.DT_VERNEEDNUM), true)
+		synth = append(synth, synthSection{".gnu.version_r", elf.SHT_GNU_VERNEED, elf.SHF_ALLOC, dyn(elf.DT_VERNEED), size, 0, ".dynstr"})
+	}
+
+	if has(elf.DT_VERDEF) {
+		size := elfFs.versionTableSize(dyn(elf.DT_VERDEF), dyn(elf.DT_VERDEFNUM), false)
+		synth = append(synth, synthSection{".gnu.version_d", elf.SHT_GNU_VERDEF, elf.SHF_ALLOC, dyn(elf.DT_VERDEF), size, 0, ".dynstr"})
+	}
+
+	/* DT_RELASZ may cover the PLT relocations too when they follow .rela.dyn */
+	pltAddr, pltSize := dyn(elf.DT_JMPREL), dyn(elf.DT_PLTRELSZ)
+	pltName, pltType, pltEnt := ".rela.plt", elf.SHT_RELA, elfFs.relSize(true)
+	if elf.DynTag(dyn(elf.DT_PLTREL)) == elf.DT_REL {
+		pltName, pltType, pltEnt = ".rel.plt", elf.SHT_REL, elfFs.relSize(false)
+	}
+	type relTable struct {
+		name            string
+		typ             elf.SectionType
+		addr, size, ent elf.DynTag
+		defaultEntsize  uint64
+	}
+	relTables := []relTable{
+		{".rela.dyn", elf.SHT_RELA, elf.DT_RELA, elf.DT_RELASZ, elf.DT_RELAENT, elfFs.relSize(true)},
+		{".rel.dyn", elf.SHT_REL, elf.DT_REL, elf.DT_RELSZ, elf.DT_RELENT, elfFs.relSize(false)},
+	}
+	/* the DT_ANDROID_* values are DT_SUNW_* ones on Solaris, as in DynTagKind */
+	android := elf.OSABI(elfFs.Ident[elf.EI_OSABI]) != elf.ELFOSABI_SOLARIS
+	if android {
+		relTables = append(relTables,
+			relTable{".rela.dyn", SHT_ANDROID_RELA, DT_ANDROID_RELA, DT_ANDROID_RELASZ, 0, 0},
+			relTable{".rel.dyn", SHT_ANDROID_REL, DT_ANDROID_REL, DT_ANDROID_RELSZ, 0, 0})
+	}
+	for _, r := range relTables {
+		if !has(r.addr) {
+			continue
+		}
+		addr, size, entsize := dyn(r.addr), dyn(r.size), dyn(r.ent)
+		if entsize == 0 {
+			entsize = r.defaultEntsize
+		}
+		if pltSize != 0 && pltType == r.typ && pltAddr >= addr && pltAddr < addr+size {
+			size = pltAddr - addr
+		}
+		synth = append(synth, synthSection{r.name, r.typ, elf.SHF_ALLOC, addr, size, entsize, ".dynsym"})
+	}
+	if has(DT_RELR) {
+		entsize := dyn(DT_RELRENT)
+		if entsize == 0 {
+			entsize = wordSize
+		}
+		synth = append(synth, synthSection{".relr.dyn", SHT_RELR, elf.SHF_ALLOC, dyn(DT_RELR), dyn(DT_RELRSZ), entsize, ""})
+	}
+	if android && has(DT_ANDROID_RELR) {
+		entsize := dyn(DT_ANDROID_RELRENT)
+		if entsize == 0 {
+			entsize = wordSize
+		}
+		synth = append(synth, synthSection{".relr.dyn", SHT_ANDROID_RELR, elf.SHF_ALLOC, dyn(DT_ANDROID_RELR), dyn(DT_ANDROID_RELRSZ), entsize, ""})
+	}
+	if has(elf.DT_JMPREL) {
+		synth = append(synth, synthSection{pltName, pltType, elf.SHF_ALLOC | elf.SHF_INFO_LINK, pltAddr, pltSize, pltEnt, ".dynsym"})
+	}
+
+	for _, a := range []struct {
+		name      string
+		typ       elf.SectionType
+		addr, len elf.DynTag
+	}{
+		{".preinit_array", elf.SHT_PREINIT_ARRAY, elf.DT_PREINIT_ARRAY, elf.DT_PREINIT_ARRAYSZ},
+		{".init_array", elf.SHT_INIT_ARRAY, elf.DT_INIT_ARRAY, elf.DT_INIT_ARRAYSZ},
+		{".fini_array", elf.SHT_FINI_ARRAY, elf.DT_FINI_ARRAY, elf.DT_FINI_ARRAYSZ},
+	} {
+		if has(a.addr) {
+			synth = append(synth, synthSection{a.name, a.typ, elf.SHF_ALLOC | elf.SHF_WRITE, dyn(a.addr), dyn(a.len), wordSize, ""})
+		}
+	}
+
+	sections, names := elfFs.ElfSections.Section, elfFs.ElfSections.SectionName
+	if len(sections) == 0 {
+		sections, names = []Section{{Synthetic: true}}, []string{""}
+	}
+
+	/* ndx is where each rebuilt section ends up, a real one of the same type and address stands in for it */
+	ndx := map[string]uint32{}
+	add := func(name string, sec Section) {
+		for i, real := range sections {
+			if i > 0 && !real.Synthetic && real.Type == sec.Type && real.Addr == sec.Addr {
+				ndx[name] = uint32(i)
+				return
+			}
+		}
+		sec.Synthetic = true
+		ndx[name] = uint32(len(sections))
+		sections = append(sections, sec)
+		names = append(names, name)
+	}
+
+	/* .interp and .dynamic are known by file offset already */
+	for _, prog := range elfFs.ProgHeaders {
+		var sec Section
+		var name string
+		switch prog.Type {
+		case elf.PT_INTERP:
+			sec = Section{Type: elf.SHT_PROGBITS, Flags: elf.SHF_ALLOC, Addralign: 1}
+			name = ".interp"
+		case elf.PT_DYNAMIC:
+			sec = Section{Type: elf.SHT_DYNAMIC, Flags: elf.SHF_ALLOC | elf.SHF_WRITE, Entsize: 2 * wordSize, Addralign: wordSize}
+			name = ".dynamic"
+		default:
+			continue
+		}
+		sec.Addr, sec.Off, sec.Size = prog.Vaddr, prog.Off, prog.Filesz
+		add(name, sec)
+	}
+
+	sort.SliceStable(synth, func(i, j int) bool { return synth[i].addr < synth[j].addr })
+	for _, s := range synth {
+		off, ok := elfFs.VaddrToOff(s.addr)
+		if !ok {
+			elfFs.anomaly(newFormatError(ErrOutOfRange, 0, "dynamic section", "%s at 0x%x isn't mapped by any PT_LOAD", s.name, s.addr))
+			continue
+		}
+		add(s.name, Section{
+			Type:      s.typ,
+			Flags:     s.flags,
+			Addr:      s.addr,
+			Off:       off,
+			Size:      s.size,
+			Addralign: wordSize,
+			Entsize:   s.entsize,
+		})
+	}
+
+	elfFs.ElfSections.Section = sections
+	elfFs.ElfSections.SectionName = names
+
+	/* links are resolved once every section has its final index, real sections keep theirs */
+	rebuilt := func(name string) (uint32, bool) {
+		i, ok := ndx[name]
+		return i, ok && sections[i].Synthetic
+	}
+	for _, s := range synth {
+		if i, ok := rebuilt(s.name); ok && s.link != "" {
+			sections[i].Link = ndx[s.link]
+		}
+	}
+
+	/* sh_info of the version sections is their entry count */
+	if i, ok := rebuilt(".gnu.version_r"); ok {
+		sections[i].Info = uint32(dyn(elf.DT_VERNEEDNUM))
+	}
+	if i, ok := rebuilt(".gnu.version_d"); ok {
+		sections[i].Info = uint32(dyn(elf.DT_VERDEFNUM))
+	}
+	return nil
+}
+
+// dynSymCount works out how many entries .dynsym has, which no dynamic
+// tag records: the SysV hash chain count, else the highest index reachable
+// through the GNU hash table, else the gap up to the string table that
+// linkers place right after it.
+func (elfFs *ELFFile) dynSymCount() uint64 {
+	if addr, ok := elfFs.DynValue(elf.DT_HASH); ok {
+		if _, nchain := elfFs.sysvHashSize(addr); nchain != 0 {
+			return nchain
+		}
+	}
+
+	if addr, ok := elfFs.DynValue(elf.DT_GNU_HASH); ok {
+		if n := elfFs.gnuHashSymCount(addr); n != 0 {
+			return n
+		}
+	}
+
+	symtab, _ := elfFs.DynValue(elf.DT_SYMTAB)
+	strtab, ok := elfFs.DynValue(elf.DT_STRTAB)
+	if ok && strtab > symtab {
+		return (strtab - symtab) / elfFs.symSize()
+	}
+	return 0
+}
+
+// readVaddr reads size bytes at virtual address addr.
+func (elfFs *ELFFile) readVaddr(structure string, addr, size uint64) ([]byte, error) {
+	off, ok := elfFs.VaddrToOff(addr)
+	if !ok {
+		return nil, newFormatError(ErrOutOfRange, 0, structure, "address 0x%x isn't mapped by any PT_LOAD", addr)
+	}
+	return elfFs.readBytes(structure, off, size)
+}
+
+func (elfFs *ELFFile) sysvHashSize(addr uint64) (nbucket, nchain uint64) {
+	hdr, err := elfFs.readVaddr("hash table", addr, 8)
+	if err != nil {
+		return 0, 0
+	}
+	bo := elfFs.FileHdr.Endianness
+	return uint64(bo.Uint32(hdr)), uint64(bo.Uint32(hdr[4:]))
+}
+
+// gnuHashSymCount finds the last symbol in the GNU hash chains: take the
+// highest bucket start and follow its chain until the end-of-chain bit.
+func (elfFs *ELFFile) gnuHashSymCount(addr uint64) uint64 {
+	bo := elfFs.FileHdr.Endianness
+	hdr, err := elfFs.readVaddr("gnu hash table", addr, 16)
+	if err != nil {
+		return 0
+	}
+	nbuckets, bloomSize := uint64(bo.Uint32(hdr)), uint64(bo.Uint32(hdr[8:]))
+
+	wordSize := uint64(8)
+	if elfFs.FileHdr.Arch == elf.ELFCLASS32 {
+		wordSize = 4
+	}
+	bucketsAddr := addr + 16 + bloomSize*wordSize
+	buckets, err := elfFs.readVaddr("gnu hash table", bucketsAddr, nbuckets*4)
+	if err != nil {
+		return 0
+	}
+
+	var last uint64
+	for i := uint64(0); i < nbuckets; i++ {
+		if b := uint64(bo.Uint32(buckets[i*4:])); b > last {
+			last = b
+		}
+	}
+	if last == 0 {
+		return uint64(bo.Uint32(hdr[4:]))
+	}
+
+	symoffset := uint64(bo.Uint32(hdr[4:]))
+	if last < symoffset {
+		return 0
+	}
+	chainAddr := bucketsAddr + nbuckets*4
+	for n := 0; n < maxChainWalk; n++ {
+		v, err := elfFs.readVaddr("gnu hash table", chainAddr+(last-symoffset)*4, 4)
+		if err != nil {
+			return 0
+		}
+		if bo.Uint32(v)&1 != 0 {
+			return last + 1
+		}
+		last++
+	}
+	return 0
+}
+
+/* a corrupted chain without an end bit is not followed forever */
+const maxChainWalk = 1 << 20
+
+func (elfFs *ELFFile) gnuHashSize(addr uint64, numSyms uint64, wordSize uint64) uint64 {
+	hdr, err := elfFs.readVaddr("gnu hash table", addr, 16)
+	if err != nil {
+		return 0
+	}
+	bo := elfFs.FileHdr.Endianness
+	nbuckets, symoffset, bloomSize := uint64(bo.Uint32(hdr)), uint64(bo.Uint32(hdr[4:])), uint64(bo.Uint32(hdr[8:]))
+
+	size := 16 + bloomSize*wordSize + nbuckets*4
+	if numSyms > symoffset {
+		size += (numSyms - symoffset) * 4
+	}
+	return size
+}
@@ -0,0 +1,120 @@
+package elfparse
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// patchDynsym returns testdata/v.so with the section header of .dynsym
+// handed to patch, along with the number of sections it has.
+func patchDynsym(t *testing.T, patch func(data []byte, shdr []byte, shstrtab []byte)) ([]byte, int) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/v.so")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shoff := binary.LittleEndian.Uint64(data[0x28:])
+	shstr := f.Sections[binary.LittleEndian.Uint16(data[0x3e:])]
+	for i, s := range f.Sections {
+		if s.Type == elf.SHT_DYNSYM {
+			patch(data, data[shoff+uint64(i)*64:][:64], data[shstr.Offset:][:shstr.Size])
+		}
+	}
+	return data, len(f.Sections)
+}
+
+func TestSynthesizeSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    func(data, shdr, shstrtab []byte)
+		added    int  // sections added to the real ones
+		rebuilt  bool // the real ones are replaced
+		dynsym   string
+		resolved bool // relocations find their symbols
+	}{
+		{
+			name: "renamed",
+			patch: func(_, _, shstrtab []byte) {
+				copy(shstrtab[bytes.Index(shstrtab, []byte(".dynsym\x00")):], ".foosym")
+			},
+			dynsym:   ".foosym",
+			resolved: true,
+		},
+		{
+			name: "retyped",
+			patch: func(_, shdr, _ []byte) {
+				binary.LittleEndian.PutUint32(shdr[4:], uint32(elf.SHT_PROGBITS))
+			},
+			added:  1,
+			dynsym: ".dynsym",
+		},
+		{
+			name: "no headers",
+			patch: func(data, _, _ []byte) {
+				binary.LittleEndian.PutUint64(data[0x28:], 0)
+				binary.LittleEndian.PutUint16(data[0x3c:], 0)
+				binary.LittleEndian.PutUint16(data[0x3e:], 0)
+			},
+			rebuilt:  true,
+			dynsym:   ".dynsym",
+			resolved: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, shnum := patchDynsym(t, tt.patch)
+			elfFs, err := NewFileOptions(bytes.NewReader(data), Options{Resilient: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sections := elfFs.ElfSections.Section
+			if got := sections[0].Synthetic; got != tt.rebuilt {
+				t.Errorf("section 0 synthetic = %v, want %v", got, tt.rebuilt)
+			}
+			if !tt.rebuilt {
+				if got := len(sections); got != shnum+tt.added {
+					t.Errorf("%d sections, want %d", got, shnum+tt.added)
+				}
+				if len(elfFs.Symbols) == 0 {
+					t.Errorf("lost the .symtab symbols")
+				}
+			}
+
+			ndx := elfFs.sectionOfType(elf.SHT_DYNSYM)
+			if got := elfFs.ElfSections.SectionName[ndx]; got != tt.dynsym {
+				t.Errorf("SHT_DYNSYM is %q, want %q", got, tt.dynsym)
+			}
+			if len(elfFs.DynSymbols) == 0 {
+				t.Errorf("no dynamic symbols")
+			}
+
+			resolved := false
+			for relNdx, rels := range elfFs.Rels {
+				for _, rel := range rels {
+					if sym, err := elfFs.RelocSymbol(relNdx, rel); err == nil && strings.HasPrefix(sym.Name, "__cxa_finalize") {
+						resolved = true
+					}
+				}
+			}
+			if resolved != tt.resolved {
+				t.Errorf("relocation against __cxa_finalize resolved = %v, want %v", resolved, tt.resolved)
+			}
+
+			for _, w := range elfFs.Warnings {
+				if errors.Is(w, ErrOutOfRange) {
+					t.Errorf("warning %v", w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+#include <stdio.h>
+static int a,b,c,d,e,f,g,h;
+static int *ptrs[] = {&a,&b,&c,&d,&e,&f,&g,&h,&a,&b,&c,&d,&e,&f,&g,&h,&a,&b,&c,&d};
+static int *lone = &b; static void *sp[8] = {&sp, &sp, &sp, &sp};
+void *ext[] = {&puts, &printf, &puts, &stdout};
+int get(int i){return *ptrs[i] + *lone + printf("x") + puts("y");}
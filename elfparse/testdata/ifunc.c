@@ -0,0 +1,6 @@
+static int impl(void) { return 1; }
+static void *resolve(void) { return impl; }
+int ifn(void) __attribute__((ifunc("resolve")));
+static int sfn(void) __attribute__((ifunc("resolve")));
+int call(void) { return ifn() + sfn(); }
+__asm__(".type uniq, @gnu_unique_object\n.globl uniq\n.pushsection .data\nuniq: .long 1\n.size uniq, 4\n.popsection");
@@ -0,0 +1,252 @@
+//go:build ignore
+
+// mkseg writes the seg-* executables segments_test.go reads, for the
+// architectures there is no cross linker for at hand: go run mkseg.go.
+// Each has the sections and segments ld lays out for a dynamically linked
+// program with TLS, plus the ones peculiar to its machine.
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"log"
+	"os"
+)
+
+type target struct {
+	file    string
+	class   elf.Class
+	order   binary.ByteOrder
+	machine elf.Machine
+	flags   uint32
+	interp  string
+	extra   []extraSection // between .note.gnu.property and .text
+}
+
+// extraSection is a machine specific section with a segment of its own.
+type extraSection struct {
+	name  string
+	typ   elf.SectionType
+	flags elf.SectionFlag
+	size  int
+	prog  elf.ProgType
+}
+
+var targets = []target{
+	{"seg-mips32be", elf.ELFCLASS32, binary.BigEndian, elf.EM_MIPS, 0x70001005, "/lib/ld.so.1", []extraSection{
+		{".MIPS.abiflags", elf.SectionType(0x7000002a), elf.SHF_ALLOC, 24, elf.PT_MIPS_ABIFLAGS},
+		{".reginfo", elf.SectionType(0x70000006), elf.SHF_ALLOC, 24, elf.PT_MIPS_REGINFO},
+	}},
+	{"seg-mips64be", elf.ELFCLASS64, binary.BigEndian, elf.EM_MIPS, 0x80000007, "/lib64/ld.so.1", []extraSection{
+		{".MIPS.abiflags", elf.SectionType(0x7000002a), elf.SHF_ALLOC, 24, elf.PT_MIPS_ABIFLAGS},
+		{".MIPS.options", elf.SectionType(0x7000000d), elf.SHF_ALLOC | elf.SectionFlag(0x08000000), 40, elf.PT_MIPS_OPTIONS},
+	}},
+	{"seg-s390x", elf.ELFCLASS64, binary.BigEndian, elf.EM_S390, 0, "/lib/ld64.so.1", nil},
+	{"seg-arm", elf.ELFCLASS32, binary.LittleEndian, elf.EM_ARM, 0x05000400, "/lib/ld-linux-armhf.so.3", []extraSection{
+		{".ARM.exidx", elf.SectionType(0x70000001), elf.SHF_ALLOC | elf.SHF_LINK_ORDER, 16, elf.PT_ARM_EXIDX},
+	}},
+	{"seg-aarch64", elf.ELFCLASS64, binary.LittleEndian, elf.EM_AARCH64, 0, "/lib/ld-linux-aarch64.so.1", nil},
+}
+
+type section struct {
+	name       string
+	typ        elf.SectionType
+	flags      elf.SectionFlag
+	addr, off  uint64
+	size       uint64
+	align      uint64
+	entsize    uint64
+	data       []byte
+	link       *section
+	nameOffset uint32
+}
+
+type segment struct {
+	typ                  elf.ProgType
+	flags                elf.ProgFlag
+	off, vaddr           uint64
+	filesz, memsz, align uint64
+}
+
+const (
+	base     = 0x400000
+	pageSize = 0x10000
+)
+
+func main() {
+	for _, t := range targets {
+		if err := os.WriteFile(t.file, t.build(), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func (t target) build() []byte {
+	word := uint64(8)
+	ehsize, phentsize, shentsize := uint64(64), uint64(56), uint64(64)
+	if t.class == elf.ELFCLASS32 {
+		word, ehsize, phentsize, shentsize = 4, 52, 32, 40
+	}
+
+	var secs []*section
+	var segs []segment
+	off := uint64(0)
+	place := func(s *section, addrDelta uint64) *section {
+		if s.align > 1 {
+			off = (off + s.align - 1) &^ (s.align - 1)
+		}
+		s.off = off
+		if s.flags&elf.SHF_ALLOC != 0 {
+			s.addr = off + addrDelta
+		}
+		if s.typ != elf.SHT_NOBITS {
+			if s.data == nil {
+				s.data = make([]byte, s.size)
+			}
+			off += s.size
+		}
+		secs = append(secs, s)
+		return s
+	}
+	span := func(typ elf.ProgType, flags elf.ProgFlag, align uint64, first, last *section) segment {
+		end := last.off + last.size
+		if last.typ == elf.SHT_NOBITS {
+			end = last.off
+		}
+		return segment{typ, flags, first.off, first.addr, end - first.off, last.addr + last.size - first.addr, align}
+	}
+
+	/* the program headers come right after the ELF header, in the first PT_LOAD */
+	nphdr := 11 + len(t.extra)
+	off = ehsize + uint64(nphdr)*phentsize
+
+	interp := place(&section{name: ".interp", typ: elf.SHT_PROGBITS, flags: elf.SHF_ALLOC, align: 1,
+		data: append([]byte(t.interp), 0), size: uint64(len(t.interp) + 1)}, base)
+	property := t.propertyNote()
+	note := place(&section{name: ".note.gnu.property", typ: elf.SHT_NOTE, flags: elf.SHF_ALLOC, align: word,
+		data: property, size: uint64(len(property))}, base)
+	var extra []*section
+	for _, e := range t.extra {
+		extra = append(extra, place(&section{name: e.name, typ: e.typ, flags: e.flags, align: word, size: uint64(e.size)}, base))
+	}
+	dynstr := place(&section{name: ".dynstr", typ: elf.SHT_STRTAB, flags: elf.SHF_ALLOC, align: 1, size: 1}, base)
+	text := place(&section{name: ".text", typ: elf.SHT_PROGBITS, flags: elf.SHF_ALLOC | elf.SHF_EXECINSTR, align: 16, size: 0x40}, base)
+	for _, e := range extra {
+		if e.flags&elf.SHF_LINK_ORDER != 0 {
+			e.link = text // .ARM.exidx is ordered like the code it unwinds
+		}
+	}
+	ehHdr := place(&section{name: ".eh_frame_hdr", typ: elf.SHT_PROGBITS, flags: elf.SHF_ALLOC, align: 4, size: 0x14}, base)
+	ehFrame := place(&section{name: ".eh_frame", typ: elf.SHT_PROGBITS, flags: elf.SHF_ALLOC, align: word, size: 0x30}, base)
+
+	/* the writable PT_LOAD is mapped a page further, as ld does */
+	tls := elf.SHF_ALLOC | elf.SHF_WRITE | elf.SHF_TLS
+	tdata := place(&section{name: ".tdata", typ: elf.SHT_PROGBITS, flags: tls, align: word, size: 2 * word}, base+pageSize)
+	tbss := place(&section{name: ".tbss", typ: elf.SHT_NOBITS, flags: tls, align: word, size: word}, base+pageSize)
+	dynamic := place(&section{name: ".dynamic", typ: elf.SHT_DYNAMIC, flags: elf.SHF_ALLOC | elf.SHF_WRITE, align: word,
+		size: 2 * word, entsize: 2 * word, link: dynstr}, base+pageSize)
+	place(&section{name: ".data", typ: elf.SHT_PROGBITS, flags: elf.SHF_ALLOC | elf.SHF_WRITE, align: word, size: 4 * word}, base+pageSize)
+	bss := place(&section{name: ".bss", typ: elf.SHT_NOBITS, flags: elf.SHF_ALLOC | elf.SHF_WRITE, align: word, size: 0x100}, base+pageSize)
+	place(&section{name: ".comment", typ: elf.SHT_PROGBITS, flags: elf.SHF_MERGE | elf.SHF_STRINGS, align: 1,
+		data: []byte("mkseg\x00"), size: 6, entsize: 1}, 0)
+
+	phdr := segment{elf.PT_PHDR, elf.PF_R, ehsize, base + ehsize, uint64(nphdr) * phentsize, uint64(nphdr) * phentsize, word}
+	text1 := segment{elf.PT_LOAD, elf.PF_R | elf.PF_X, 0, base, ehFrame.off + ehFrame.size, ehFrame.off + ehFrame.size, pageSize}
+	segs = append(segs, phdr, span(elf.PT_INTERP, elf.PF_R, 1, interp, interp), text1)
+	segs = append(segs, span(elf.PT_LOAD, elf.PF_R|elf.PF_W, pageSize, tdata, bss))
+	segs = append(segs, span(elf.PT_DYNAMIC, elf.PF_R|elf.PF_W, word, dynamic, dynamic))
+	segs = append(segs, span(elf.PT_NOTE, elf.PF_R, word, note, note))
+	segs = append(segs, span(elf.PT_GNU_PROPERTY, elf.PF_R, word, note, note))
+	for i, e := range t.extra {
+		segs = append(segs, span(e.prog, elf.PF_R, word, extra[i], extra[i]))
+	}
+	segs = append(segs, span(elf.PT_TLS, elf.PF_R, word, tdata, tbss))
+	segs = append(segs, span(elf.PT_GNU_EH_FRAME, elf.PF_R, 4, ehHdr, ehHdr))
+	segs = append(segs, segment{elf.PT_GNU_STACK, elf.PF_R | elf.PF_W, 0, 0, 0, 0, 16})
+	segs = append(segs, span(elf.PT_GNU_RELRO, elf.PF_R, 1, tdata, dynamic))
+	if len(segs) != nphdr {
+		log.Fatalf("%s: %d program headers, %d planned", t.file, len(segs), nphdr)
+	}
+
+	/* .shstrtab and the section headers close the file */
+	shstr := []byte{0}
+	for _, s := range secs {
+		s.nameOffset = uint32(len(shstr))
+		shstr = append(append(shstr, s.name...), 0)
+	}
+	shstr = append(append(shstr, ".shstrtab"...), 0)
+	place(&section{name: ".shstrtab", typ: elf.SHT_STRTAB, align: 1, nameOffset: uint32(len(shstr) - len(".shstrtab") - 1),
+		data: shstr, size: uint64(len(shstr))}, 0)
+	shoff := (off + word - 1) &^ (word - 1)
+
+	var b bytes.Buffer
+	w := func(v ...any) {
+		for _, x := range v {
+			binary.Write(&b, t.order, x)
+		}
+	}
+	addr := func(v uint64) any {
+		if t.class == elf.ELFCLASS32 {
+			return uint32(v)
+		}
+		return v
+	}
+
+	encoding := elf.ELFDATA2LSB
+	if t.order == binary.BigEndian {
+		encoding = elf.ELFDATA2MSB
+	}
+	b.Write([]byte{0x7f, 'E', 'L', 'F', byte(t.class), byte(encoding), byte(elf.EV_CURRENT), 0})
+	b.Write(make([]byte, 8))
+	w(uint16(elf.ET_EXEC), uint16(t.machine), uint32(1), addr(text.addr), addr(ehsize), addr(shoff), t.flags,
+		uint16(ehsize), uint16(phentsize), uint16(nphdr), uint16(shentsize), uint16(len(secs)+1), uint16(len(secs)))
+
+	for _, p := range segs {
+		if t.class == elf.ELFCLASS32 {
+			w(uint32(p.typ), uint32(p.off), uint32(p.vaddr), uint32(p.vaddr), uint32(p.filesz), uint32(p.memsz), uint32(p.flags), uint32(p.align))
+		} else {
+			w(uint32(p.typ), uint32(p.flags), p.off, p.vaddr, p.vaddr, p.filesz, p.memsz, p.align)
+		}
+	}
+
+	out := make([]byte, shoff)
+	copy(out, b.Bytes())
+	for _, s := range secs {
+		if s.typ != elf.SHT_NOBITS {
+			copy(out[s.off:], s.data)
+		}
+	}
+
+	b.Reset()
+	b.Write(make([]byte, shentsize))
+	for _, s := range secs {
+		var link uint32
+		if s.link != nil {
+			link = uint32(sectionIndex(secs, s.link))
+		}
+		w(s.nameOffset, uint32(s.typ), addr(uint64(s.flags)), addr(s.addr), addr(s.off), addr(s.size),
+			link, uint32(0), addr(s.align), addr(s.entsize))
+	}
+	return append(out, b.Bytes()...)
+}
+
+func sectionIndex(secs []*section, s *section) int {
+	for i, sec := range secs {
+		if sec == s {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// propertyNote is a NT_GNU_PROPERTY_TYPE_0 note holding only
+// GNU_PROPERTY_NO_COPY_ON_PROTECTED, a property that means the same on
+// every machine.
+func (t target) propertyNote() []byte {
+	var b bytes.Buffer
+	binary.Write(&b, t.order, []uint32{4, 8, 5})
+	b.WriteString("GNU\x00")
+	binary.Write(&b, t.order, []uint32{2, 0})
+	return b.Bytes()
+}
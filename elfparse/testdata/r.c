@@ -0,0 +1,4 @@
+static int a,b,c,d,e,f,g,h;
+static int *ptrs[] = {&a,&b,&c,&d,&e,&f,&g,&h,&a,&b,&c,&d,&e,&f,&g,&h,&a,&b,&c,&d,&e,&f,&g,&h,&a,&b,&c,&d,&e,&f,&g,&h,&a,&b,&c,&d,&e,&f,&g,&h,&a,&b,&c,&d,&e,&f,&g,&h,&a,&b,&c,&d,&e,&f,&g,&h,&a,&b,&c,&d,&e,&f,&g,&h,&a};
+static int *lone = &b; static void *sp[40] = {&sp, &sp, 0, &sp}; static int *far = &c;
+int get(int i){return *ptrs[i] + *lone + *far;}
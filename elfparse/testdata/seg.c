@@ -0,0 +1,8 @@
+__thread int t = 1;
+__thread int tb;
+
+void _start(void)
+{
+	for (;;)
+		t += tb;
+}
@@ -0,0 +1,4 @@
+int foo(void){return 1;}
+int foo_old(void){return 0;}
+__asm__(".symver foo_old,foo@V1");
+__asm__(".symver foo,foo@@V2");
@@ -0,0 +1,161 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+type EnumIdent struct {
+	Endianness binary.ByteOrder
+	Arch       elf.Class
+	Machine    elf.Machine
+}
+
+// Header is the ELF file header with every field widened to its 64-bit
+// size. Class records which layout it was decoded from.
+//
+// Phnum, Shnum and Shstrndx are as found in the file. SegmentCount,
+// SectionCount and ShstrIndex are the values to use: with extended
+// numbering the real ones are kept in section header 0, and e_phnum is
+// PN_XNUM, e_shnum 0 and e_shstrndx SHN_XINDEX.
+type Header struct {
+	Class     elf.Class
+	Ident     [elf.EI_NIDENT]byte
+	Type      elf.Type
+	Machine   elf.Machine
+	Version   elf.Version
+	Entry     uint64
+	Phoff     uint64
+	Shoff     uint64
+	Flags     uint32
+	Ehsize    uint16
+	Phentsize uint16
+	Phnum     uint16
+	Shentsize uint16
+	Shnum     uint16
+	Shstrndx  uint16
+
+	SegmentCount uint32
+	SectionCount uint32
+	ShstrIndex   uint32
+}
+
+// Section is a section header, decoded from either Elf32_Shdr or Elf64_Shdr.
+// Synthetic sections don't exist in the file, they were reconstructed from
+// the dynamic section because the section headers were missing or bogus.
+type Section struct {
+	Name      uint32
+	Type      elf.SectionType
+	Flags     elf.SectionFlag
+	Addr      uint64
+	Off       uint64
+	Size      uint64
+	Link      uint32
+	Info      uint32
+	Addralign uint64
+	Entsize   uint64
+	Synthetic bool
+}
+
+// Segment is a program header, decoded from either Elf32_Phdr or Elf64_Phdr.
+type Segment struct {
+	Type   elf.ProgType
+	Flags  elf.ProgFlag
+	Off    uint64
+	Vaddr  uint64
+	Paddr  uint64
+	Filesz uint64
+	Memsz  uint64
+	Align  uint64
+}
+
+// Symbol is a symbol table entry, decoded from either Elf32_Sym or Elf64_Sym,
+// with its name and section already looked up.
+type Symbol struct {
+	Index   uint32 // position in its table
+	Name    string
+	NameOff uint32 // st_name, the offset of Name in the string table
+	Info    uint8
+	Other   uint8
+	Shndx   uint16
+	Value   uint64
+	Size    uint64
+
+	// Section is the section Shndx refers to, taken from SHT_SYMTAB_SHNDX
+	// for SHN_XINDEX, and equal to Shndx for the other reserved indexes.
+	// SectionName is its name, or "UND", "ABS" and "COM" for SHN_UNDEF,
+	// SHN_ABS and SHN_COMMON.
+	Section     uint32
+	SectionName string
+}
+
+func (s Symbol) Type() elf.SymType      { return elf.ST_TYPE(s.Info) }
+func (s Symbol) Bind() elf.SymBind      { return elf.ST_BIND(s.Info) }
+func (s Symbol) Visibility() elf.SymVis { return elf.ST_VISIBILITY(s.Other) }
+
+// Reloc is a REL or RELA entry. Info is kept as found in the file, Sym and
+// Type are already split out of it according to the file's class.
+type Reloc struct {
+	Off       uint64
+	Info      uint64
+	Sym       uint32
+	Type      uint32
+	Addend    int64
+	HasAddend bool
+}
+
+// RelrTable is a decoded SHT_RELR section: Words address and bitmap
+// entries standing for a relative relocation at each of Addrs.
+type RelrTable struct {
+	Words int
+	Addrs []uint64
+}
+
+type SHDRTable struct {
+	Section     []Section
+	SectionName []string
+}
+
+// ELFFile is the parsed model of an ELF binary. All tables are normalized to
+// the class independent types above, FileHdr.Arch tells which class the
+// binary actually is.
+type ELFFile struct {
+	Fh          io.ReaderAt
+	Ident       [16]byte
+	FileHdr     EnumIdent
+	Hdr         Header
+	ElfSections SHDRTable
+	ProgHeaders []Segment
+	Dynamic     []DynEntry
+	Notes       []NoteTable
+	Size        int64
+
+	Symbols    []Symbol           // .symtab, in table order
+	DynSymbols []Symbol           // .dynsym, in table order
+	Rels       map[uint32][]Reloc // relocation entries are mapped to section index
+	Relrs      map[uint32]RelrTable
+
+	Versym  []uint16 // .gnu.version, indexed like DynSymbols
+	Verneed []VerNeed
+	Verdef  []VerDef
+
+	Hash    *HashTable // .hash, nil if there is none
+	GNUHash *HashTable // .gnu.hash, nil if there is none
+
+	// Warnings lists the anomalies tolerated by a resilient parse.
+	Warnings []error
+
+	dynstr    []byte
+	dynstrOff uint64
+	resilient bool
+	closer    io.Closer
+	addrOnce  sync.Once  // Addr2Sym may be called from several goroutines
+	addrIndex []symRange // built by the first Addr2Sym
+}
+
+const (
+	DynSym int = 0xa
+	Sym    int = 0xb
+)
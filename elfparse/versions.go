@@ -0,0 +1,293 @@
+package elfparse
+
+import (
+	"debug/elf"
+	"fmt"
+)
+
+// VerNeed is an Elf_Verneed entry of .gnu.version_r: the versions needed
+// from one shared library.
+type VerNeed struct {
+	Version uint16
+	File    string
+	Off     uint64 // offset within the section
+	Aux     []VernAux
+}
+
+// VernAux is one version needed from VerNeed.File.
+type VernAux struct {
+	Hash  uint32
+	Flags uint16
+	Other uint16 // the version index .gnu.version refers to
+	Name  string
+	Off   uint64
+}
+
+// VerDef is an Elf_Verdef entry of .gnu.version_d. Names[0] is the
+// version defined, any further names are the versions it inherits from.
+type VerDef struct {
+	Version uint16
+	Flags   uint16
+	Ndx     uint16
+	Hash    uint32
+	Names   []string
+	NameOff []uint64 // offset of the Elf_Verdaux of each name
+	Off     uint64
+}
+
+const (
+	VER_NDX_LOCAL  = 0
+	VER_NDX_GLOBAL = 1
+	VERSYM_HIDDEN  = 0x8000
+
+	VER_FLG_BASE = 0x1
+	VER_FLG_WEAK = 0x2
+	VER_FLG_INFO = 0x4
+)
+
+const (
+	verneedSize = 16
+	vernauxSize = 16
+	verdefSize  = 20
+	verdauxSize = 8
+)
+
+/* each chain is only followed as far as the file or this many entries */
+const maxVerEntries = 1 << 16
+
+/* how much of the file a synthesized version section may span */
+const maxVerTable = 1 << 20
+
+func (elfFs *ELFFile) getVersions() error {
+	elfFs.Versym, elfFs.Verneed, elfFs.Verdef = nil, nil, nil
+
+	if ndx := elfFs.sectionOfType(elf.SHT_GNU_VERSYM); ndx != 0 {
+		data, err := elfFs.sectionTable("version symbols "+elfFs.ElfSections.SectionName[ndx], ndx)
+		if err := elfFs.anomaly(err); err != nil {
+			return err
+		}
+		elfFs.Versym = make([]uint16, len(data)/2)
+		for i := range elfFs.Versym {
+			elfFs.Versym[i] = elfFs.FileHdr.Endianness.Uint16(data[i*2:])
+		}
+	}
+
+	if ndx := elfFs.sectionOfType(elf.SHT_GNU_VERNEED); ndx != 0 {
+		data, strtab, err := elfFs.versionTable(ndx)
+		if err == nil {
+			elfFs.Verneed, _, err = elfFs.walkVerneed(data, strtab, uint64(elfFs.ElfSections.Section[ndx].Info), "version needs "+elfFs.ElfSections.SectionName[ndx])
+		}
+		if err := elfFs.anomaly(err); err != nil {
+			return err
+		}
+	}
+
+	if ndx := elfFs.sectionOfType(elf.SHT_GNU_VERDEF); ndx != 0 {
+		data, strtab, err := elfFs.versionTable(ndx)
+		if err == nil {
+			elfFs.Verdef, _, err = elfFs.walkVerdef(data, strtab, uint64(elfFs.ElfSections.Section[ndx].Info), "version definitions "+elfFs.ElfSections.SectionName[ndx])
+		}
+		if err := elfFs.anomaly(err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (elfFs *ELFFile) sectionOfType(t elf.SectionType) uint32 {
+	if ndx := elfFs.SectionsByType(t); len(ndx) > 0 {
+		return ndx[0]
+	}
+	return 0
+}
+
+// versionTable reads a verneed or verdef section and the string table its
+// sh_link names, falling back to the one DT_STRTAB points at.
+func (elfFs *ELFFile) versionTable(ndx uint32) ([]byte, []byte, error) {
+	sec := elfFs.ElfSections.Section[ndx]
+	data, err := elfFs.sectionTable("version section "+elfFs.ElfSections.SectionName[ndx], ndx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if sec.Link == 0 || sec.Link >= uint32(len(elfFs.ElfSections.Section)) {
+		if elfFs.dynstr == nil {
+			return nil, nil, newFormatError(ErrBadLink, sec.Off, "version section "+elfFs.ElfSections.SectionName[ndx], "sh_link %d", sec.Link)
+		}
+		return data, elfFs.dynstr, nil
+	}
+	strtab, err := elfFs.sectionTable("string table "+elfFs.ElfSections.SectionName[sec.Link], sec.Link)
+	return data, strtab, err
+}
+
+// walkVerneed decodes count Elf_Verneed entries, or follows vn_next until
+// it is zero when count is unknown. It also returns how many bytes of data
+// the chain spans, which is how big a synthesized .gnu.version_r is.
+func (elfFs *ELFFile) walkVerneed(data, strtab []byte, count uint64, structure string) ([]VerNeed, uint64, error) {
+	bo := elfFs.FileHdr.Endianness
+	if count == 0 {
+		count = maxVerEntries
+	}
+
+	var needs []VerNeed
+	var extent uint64
+	for off := uint64(0); uint64(len(needs)) < count; {
+		if off+verneedSize > uint64(len(data)) {
+			return needs, extent, newFormatError(ErrTruncated, off, structure, "verneed %d past the end of the section", len(needs))
+		}
+		e := data[off:]
+		need := VerNeed{Version: bo.Uint16(e), File: getSectionName(bo.Uint32(e[4:]), strtab), Off: off}
+		cnt, aux, next := uint64(bo.Uint16(e[2:])), uint64(bo.Uint32(e[8:])), uint64(bo.Uint32(e[12:]))
+		extent = max(extent, off+verneedSize)
+
+		for a, auxOff := uint64(0), off+aux; a < cnt; a++ {
+			if auxOff+vernauxSize > uint64(len(data)) {
+				return needs, extent, newFormatError(ErrTruncated, auxOff, structure, "vernaux %d of %s past the end of the section", a, need.File)
+			}
+			e := data[auxOff:]
+			need.Aux = append(need.Aux, VernAux{
+				Hash:  bo.Uint32(e),
+				Flags: bo.Uint16(e[4:]),
+				Other: bo.Uint16(e[6:]),
+				Name:  getSectionName(bo.Uint32(e[8:]), strtab),
+				Off:   auxOff,
+			})
+			extent = max(extent, auxOff+vernauxSize)
+			if bo.Uint32(e[12:]) == 0 {
+				break
+			}
+			auxOff += uint64(bo.Uint32(e[12:]))
+		}
+
+		needs = append(needs, need)
+		if next == 0 {
+			break
+		}
+		off += next
+	}
+	return needs, extent, nil
+}
+
+// walkVerdef is walkVerneed for Elf_Verdef entries.
+func (elfFs *ELFFile) walkVerdef(data, strtab []byte, count uint64, structure string) ([]VerDef, uint64, error) {
+	bo := elfFs.FileHdr.Endianness
+	if count == 0 {
+		count = maxVerEntries
+	}
+
+	var defs []VerDef
+	var extent uint64
+	for off := uint64(0); uint64(len(defs)) < count; {
+		if off+verdefSize > uint64(len(data)) {
+			return defs, extent, newFormatError(ErrTruncated, off, structure, "verdef %d past the end of the section", len(defs))
+		}
+		e := data[off:]
+		def := VerDef{Version: bo.Uint16(e), Flags: bo.Uint16(e[2:]), Ndx: bo.Uint16(e[4:]), Hash: bo.Uint32(e[8:]), Off: off}
+		cnt, aux, next := uint64(bo.Uint16(e[6:])), uint64(bo.Uint32(e[12:])), uint64(bo.Uint32(e[16:]))
+		extent = max(extent, off+verdefSize)
+
+		for a, auxOff := uint64(0), off+aux; a < cnt; a++ {
+			if auxOff+verdauxSize > uint64(len(data)) {
+				return defs, extent, newFormatError(ErrTruncated, auxOff, structure, "verdaux %d of verdef %d past the end of the section", a, def.Ndx)
+			}
+			e := data[auxOff:]
+			def.Names = append(def.Names, getSectionName(bo.Uint32(e), strtab))
+			def.NameOff = append(def.NameOff, auxOff)
+			extent = max(extent, auxOff+verdauxSize)
+			if bo.Uint32(e[4:]) == 0 {
+				break
+			}
+			auxOff += uint64(bo.Uint32(e[4:]))
+		}
+
+		defs = append(defs, def)
+		if next == 0 {
+			break
+		}
+		off += next
+	}
+	return defs, extent, nil
+}
+
+// versionTableSize works out the size of a verneed or verdef table at addr
+// for synthesized sections; no dynamic tag records it.
+func (elfFs *ELFFile) versionTableSize(addr, count uint64, need bool) uint64 {
+	off, ok := elfFs.VaddrToOff(addr)
+	if !ok || uint64(elfFs.Size) <= off {
+		return 0
+	}
+	data, err := elfFs.readTable("version section", off, min(uint64(elfFs.Size)-off, maxVerTable))
+	if err != nil {
+		return 0
+	}
+
+	var extent uint64
+	if need {
+		_, extent, _ = elfFs.walkVerneed(data, nil, count, "version needs")
+	} else {
+		_, extent, _ = elfFs.walkVerdef(data, nil, count, "version definitions")
+	}
+	return extent
+}
+
+// SymbolVersion returns the version of dynamic symbol symNdx and whether
+// it is hidden, i.e. not the default version of a defined symbol. ok is
+// false for unversioned, local and global symbols.
+func (elfFs *ELFFile) SymbolVersion(symNdx uint32) (name string, hidden bool, ok bool) {
+	if symNdx >= uint32(len(elfFs.Versym)) {
+		return "", false, false
+	}
+	ndx := elfFs.Versym[symNdx] &^ VERSYM_HIDDEN
+	hidden = elfFs.Versym[symNdx]&VERSYM_HIDDEN != 0
+	if ndx == VER_NDX_LOCAL || ndx == VER_NDX_GLOBAL {
+		return "", false, false
+	}
+
+	if name, ok := elfFs.VersionName(ndx); ok {
+		return name, hidden, true
+	}
+	return fmt.Sprintf("<corrupt: %d>", ndx), hidden, true
+}
+
+// VersionName resolves a version index as found in .gnu.version.
+func (elfFs *ELFFile) VersionName(ndx uint16) (string, bool) {
+	for _, def := range elfFs.Verdef {
+		if def.Ndx == ndx && len(def.Names) > 0 {
+			return def.Names[0], true
+		}
+	}
+	for _, need := range elfFs.Verneed {
+		for _, aux := range need.Aux {
+			if aux.Other == ndx {
+				return aux.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// VersionedName appends the version of dynamic symbol symNdx to name the
+// way the linker spells it: name@VER for references and hidden
+// definitions, name@@VER for the default definition. A definition with a
+// version of another object, as copy relocations make, is a reference.
+func (elfFs *ELFFile) VersionedName(symNdx uint32, name string) string {
+	ver, hidden, ok := elfFs.SymbolVersion(symNdx)
+	if !ok || name == "" {
+		return name
+	}
+	if symNdx < uint32(len(elfFs.DynSymbols)) && elfFs.DynSymbols[symNdx].Shndx != uint16(elf.SHN_UNDEF) && !hidden &&
+		elfFs.definesVersion(elfFs.Versym[symNdx]&^VERSYM_HIDDEN) {
+		return name + "@@" + ver
+	}
+	return name + "@" + ver
+}
+
+// definesVersion reports whether version index ndx is one of .gnu.version_d.
+func (elfFs *ELFFile) definesVersion(ndx uint16) bool {
+	for _, def := range elfFs.Verdef {
+		if def.Ndx == ndx {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,8 @@
+module github.com/sad0p/go-readelf
+
+go 1.21
+
+require (
+	github.com/ianlancetaylor/demangle v0.0.0-20260724033716-83e58baca724
+	gopkg.in/yaml.v3 v3.0.1
+)